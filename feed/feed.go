@@ -0,0 +1,533 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/goplus/dql/stream"
+)
+
+var (
+	ErrNotFound      = errors.New("entity not found")
+	ErrMultiEntities = errors.New("too many entities found")
+)
+
+// nopIter is a no-operation iterator that yields no values.
+func nopIter[T any](yield func(T) bool) {}
+
+// -----------------------------------------------------------------------------
+
+// Enclosure represents a media attachment linked from a feed item.
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// Item represents a single entry shared by RSS, Atom, and JSON Feed sources.
+type Item struct {
+	Title      string
+	Link       string
+	Author     string
+	Published  time.Time
+	Updated    time.Time
+	Content    string
+	Summary    string
+	Categories []string
+	GUID       string
+	Enclosures []Enclosure
+}
+
+// Feed represents a parsed RSS, Atom, or JSON Feed document.
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []*Item
+}
+
+// -----------------------------------------------------------------------------
+
+// FeedSet represents a set of Feeds.
+type FeedSet struct {
+	Data iter.Seq[*Feed]
+	Err  error
+}
+
+// New parses a single RSS, Atom, or JSON Feed document from r and returns a
+// FeedSet containing it. If there is an error during parsing, the FeedSet's
+// Err field is set.
+func New(r io.Reader) FeedSet {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return FeedSet{Err: err}
+	}
+	f, err := parse(b)
+	if err != nil {
+		return FeedSet{Err: err}
+	}
+	return FeedSet{
+		Data: func(yield func(*Feed) bool) {
+			yield(f)
+		},
+	}
+}
+
+// Source creates a FeedSet from various types of sources:
+// - string: treated as an URL to read feed content from.
+// - []byte: treated as raw feed content.
+// - io.Reader: reads feed content from the reader.
+// - iter.Seq[*Feed]: directly uses the provided sequence of feeds.
+// - FeedSet: returns the provided FeedSet as is.
+// If the source type is unsupported, it panics.
+func Source(r any) (ret FeedSet) {
+	switch v := r.(type) {
+	case string:
+		f, err := stream.Open(v)
+		if err != nil {
+			return FeedSet{Err: err}
+		}
+		defer f.Close()
+		return New(f)
+	case []byte:
+		return New(bytes.NewReader(v))
+	case io.Reader:
+		return New(v)
+	case iter.Seq[*Feed]:
+		return FeedSet{Data: v}
+	case FeedSet:
+		return v
+	default:
+		panic("dql/feed.Source: unsupport source type")
+	}
+}
+
+// XGo_Enum returns an iterator over the feeds in the FeedSet.
+func (p FeedSet) XGo_Enum() iter.Seq[*Feed] {
+	if p.Err != nil {
+		return nopIter[*Feed]
+	}
+	return p.Data
+}
+
+// XGo_Item returns an ItemSet containing all items of the feeds in the FeedSet.
+func (p FeedSet) XGo_Item() ItemSet {
+	if p.Err != nil {
+		return ItemSet{Err: p.Err}
+	}
+	return ItemSet{
+		Data: func(yield func(*Item) bool) {
+			ok := true
+			p.Data(func(f *Feed) bool {
+				for _, it := range f.Items {
+					if ok = yield(it); !ok {
+						break
+					}
+				}
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_0 returns the first feed in the FeedSet, or ErrNotFound if the set is empty.
+func (p FeedSet) XGo_0() (val *Feed, err error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	err = ErrNotFound
+	p.Data(func(f *Feed) bool {
+		val, err = f, nil
+		return false
+	})
+	return
+}
+
+// XGo_1 returns the first feed in the FeedSet, or ErrNotFound if the set is empty.
+// If there is more than one feed in the set, ErrMultiEntities is returned.
+func (p FeedSet) XGo_1() (val *Feed, err error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	first := true
+	err = ErrNotFound
+	p.Data(func(f *Feed) bool {
+		if first {
+			val, err = f, nil
+			first = false
+			return true
+		}
+		err = ErrMultiEntities
+		return false
+	})
+	return
+}
+
+// -----------------------------------------------------------------------------
+
+// ItemSet represents a set of feed Items.
+type ItemSet struct {
+	Data iter.Seq[*Item]
+	Err  error
+}
+
+// XGo_Enum returns an iterator over the items in the ItemSet.
+func (p ItemSet) XGo_Enum() iter.Seq[*Item] {
+	if p.Err != nil {
+		return nopIter[*Item]
+	}
+	return p.Data
+}
+
+// XGo_Since returns an ItemSet containing the items published or updated at
+// or after t.
+func (p ItemSet) XGo_Since(t time.Time) ItemSet {
+	if p.Err != nil {
+		return p
+	}
+	return ItemSet{
+		Data: func(yield func(*Item) bool) {
+			p.Data(func(it *Item) bool {
+				ts := it.Published
+				if it.Updated.After(ts) {
+					ts = it.Updated
+				}
+				if !ts.Before(t) {
+					return yield(it)
+				}
+				return true
+			})
+		},
+	}
+}
+
+// XGo_Category returns an ItemSet containing the items tagged with the given category.
+func (p ItemSet) XGo_Category(name string) ItemSet {
+	if p.Err != nil {
+		return p
+	}
+	return ItemSet{
+		Data: func(yield func(*Item) bool) {
+			p.Data(func(it *Item) bool {
+				for _, c := range it.Categories {
+					if strings.EqualFold(c, name) {
+						return yield(it)
+					}
+				}
+				return true
+			})
+		},
+	}
+}
+
+// XGo_0 returns the first item in the ItemSet, or ErrNotFound if the set is empty.
+func (p ItemSet) XGo_0() (val *Item, err error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	err = ErrNotFound
+	p.Data(func(it *Item) bool {
+		val, err = it, nil
+		return false
+	})
+	return
+}
+
+// XGo_1 returns the first item in the ItemSet, or ErrNotFound if the set is empty.
+// If there is more than one item in the set, ErrMultiEntities is returned.
+func (p ItemSet) XGo_1() (val *Item, err error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	first := true
+	err = ErrNotFound
+	p.Data(func(it *Item) bool {
+		if first {
+			val, err = it, nil
+			first = false
+			return true
+		}
+		err = ErrMultiEntities
+		return false
+	})
+	return
+}
+
+// -----------------------------------------------------------------------------
+
+// parse sniffs the content type of b and dispatches to the matching format parser.
+func parse(b []byte) (*Feed, error) {
+	trimmed := bytes.TrimLeft(b, " \t\r\n")
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseJSONFeed(trimmed)
+	}
+	return parseXMLFeed(b)
+}
+
+// -----------------------------------------------------------------------------
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Author      string        `xml:"author"`
+	Creator     string        `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string        `xml:"pubDate"`
+	Description string        `xml:"description"`
+	Content     string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Categories  []string      `xml:"category"`
+	GUID        string        `xml:"guid"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Title    string      `xml:"title"`
+	Links    []atomLink  `xml:"link"`
+	Subtitle string      `xml:"subtitle"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title      string     `xml:"title"`
+	Links      []atomLink `xml:"link"`
+	Author     atomAuthor `xml:"author"`
+	Published  string     `xml:"published"`
+	Updated    string     `xml:"updated"`
+	Summary    string     `xml:"summary"`
+	Content    string     `xml:"content"`
+	Categories []atomCat  `xml:"category"`
+	ID         string     `xml:"id"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCat struct {
+	Term string `xml:"term,attr"`
+}
+
+var rfc822Layouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC3339,
+}
+
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range rfc822Layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func atomLinkHref(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+func parseXMLFeed(b []byte) (*Feed, error) {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	dec.Strict = false
+	tok, err := peekRootElement(dec)
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case "feed":
+		var af atomFeed
+		if err := xml.Unmarshal(b, &af); err != nil {
+			return nil, err
+		}
+		items := make([]*Item, len(af.Entries))
+		for i, e := range af.Entries {
+			cats := make([]string, len(e.Categories))
+			for j, c := range e.Categories {
+				cats[j] = c.Term
+			}
+			items[i] = &Item{
+				Title:      e.Title,
+				Link:       atomLinkHref(e.Links),
+				Author:     e.Author.Name,
+				Published:  parseTime(e.Published),
+				Updated:    parseTime(e.Updated),
+				Content:    e.Content,
+				Summary:    e.Summary,
+				Categories: cats,
+				GUID:       e.ID,
+			}
+		}
+		return &Feed{
+			Title:       af.Title,
+			Link:        atomLinkHref(af.Links),
+			Description: af.Subtitle,
+			Items:       items,
+		}, nil
+	default:
+		var rf rssFeed
+		if err := xml.Unmarshal(b, &rf); err != nil {
+			return nil, err
+		}
+		items := make([]*Item, len(rf.Channel.Items))
+		for i, it := range rf.Channel.Items {
+			author := it.Author
+			if author == "" {
+				author = it.Creator
+			}
+			content := it.Content
+			if content == "" {
+				content = it.Description
+			}
+			var encs []Enclosure
+			if it.Enclosure != nil {
+				encs = []Enclosure{{URL: it.Enclosure.URL, Type: it.Enclosure.Type, Length: it.Enclosure.Length}}
+			}
+			items[i] = &Item{
+				Title:      it.Title,
+				Link:       it.Link,
+				Author:     author,
+				Published:  parseTime(it.PubDate),
+				Content:    content,
+				Summary:    it.Description,
+				Categories: it.Categories,
+				GUID:       it.GUID,
+				Enclosures: encs,
+			}
+		}
+		return &Feed{
+			Title:       rf.Channel.Title,
+			Link:        rf.Channel.Link,
+			Description: rf.Channel.Description,
+			Items:       items,
+		}, nil
+	}
+}
+
+// peekRootElement returns the name of the document's root XML element
+// without consuming the rest of the decoder.
+func peekRootElement(dec *xml.Decoder) (string, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local, nil
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type jsonFeed struct {
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        jsonFeedAuthor       `json:"author"`
+	Tags          []string             `json:"tags"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size_in_bytes"`
+}
+
+func parseJSONFeed(b []byte) (*Feed, error) {
+	var jf jsonFeed
+	if err := json.Unmarshal(b, &jf); err != nil {
+		return nil, err
+	}
+	items := make([]*Item, len(jf.Items))
+	for i, it := range jf.Items {
+		content := it.ContentHTML
+		if content == "" {
+			content = it.ContentText
+		}
+		encs := make([]Enclosure, len(it.Attachments))
+		for j, a := range it.Attachments {
+			encs[j] = Enclosure{URL: a.URL, Type: a.MimeType, Length: a.Size}
+		}
+		items[i] = &Item{
+			Title:      it.Title,
+			Link:       it.URL,
+			Author:     it.Author.Name,
+			Published:  parseTime(it.DatePublished),
+			Updated:    parseTime(it.DateModified),
+			Content:    content,
+			Summary:    it.Summary,
+			Categories: it.Tags,
+			GUID:       it.ID,
+			Enclosures: encs,
+		}
+	}
+	return &Feed{
+		Title:       jf.Title,
+		Link:        jf.HomePageURL,
+		Description: jf.Description,
+		Items:       items,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,96 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const rssTestDoc = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+  <title>Example Feed</title>
+  <link>https://example.com</link>
+  <description>An example</description>
+  <item>
+    <title>First post</title>
+    <link>https://example.com/1</link>
+    <pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate>
+    <category>go</category>
+  </item>
+  <item>
+    <title>Second post</title>
+    <link>https://example.com/2</link>
+    <pubDate>Tue, 03 Jan 2006 15:04:05 +0000</pubDate>
+    <category>news</category>
+  </item>
+</channel></rss>`
+
+const jsonFeedTestDoc = `{
+  "title": "Example JSON Feed",
+  "home_page_url": "https://example.com",
+  "items": [
+    {"id": "1", "title": "Only post", "url": "https://example.com/1"}
+  ]
+}`
+
+func TestParseRSS(t *testing.T) {
+	f, err := New(strings.NewReader(rssTestDoc)).XGo_0()
+	if err != nil {
+		t.Fatalf("New().XGo_0() error: %v", err)
+	}
+	if f.Title != "Example Feed" {
+		t.Errorf("Title = %q, want %q", f.Title, "Example Feed")
+	}
+
+	var titles []string
+	New(strings.NewReader(rssTestDoc)).XGo_Item().XGo_Enum()(func(it *Item) bool {
+		titles = append(titles, it.Title)
+		return true
+	})
+	if want := []string{"First post", "Second post"}; strings.Join(titles, ",") != strings.Join(want, ",") {
+		t.Errorf("item titles = %v, want %v", titles, want)
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	f, err := New(strings.NewReader(jsonFeedTestDoc)).XGo_0()
+	if err != nil {
+		t.Fatalf("New().XGo_0() error: %v", err)
+	}
+	if f.Title != "Example JSON Feed" {
+		t.Errorf("Title = %q, want %q", f.Title, "Example JSON Feed")
+	}
+	if len(f.Items) != 1 || f.Items[0].Title != "Only post" {
+		t.Errorf("Items = %+v, want a single \"Only post\" item", f.Items)
+	}
+}
+
+func TestItemCategory(t *testing.T) {
+	var titles []string
+	New(strings.NewReader(rssTestDoc)).XGo_Item().XGo_Category("go").XGo_Enum()(func(it *Item) bool {
+		titles = append(titles, it.Title)
+		return true
+	})
+	if want := []string{"First post"}; strings.Join(titles, ",") != strings.Join(want, ",") {
+		t.Errorf("XGo_Category(\"go\") titles = %v, want %v", titles, want)
+	}
+}
+
+func TestItemSince(t *testing.T) {
+	cutoff := time.Date(2006, 1, 3, 0, 0, 0, 0, time.UTC)
+	var titles []string
+	New(strings.NewReader(rssTestDoc)).XGo_Item().XGo_Since(cutoff).XGo_Enum()(func(it *Item) bool {
+		titles = append(titles, it.Title)
+		return true
+	})
+	if want := []string{"Second post"}; strings.Join(titles, ",") != strings.Join(want, ",") {
+		t.Errorf("XGo_Since(%v) titles = %v, want %v", cutoff, titles, want)
+	}
+}
+
+func TestInvalidFeed(t *testing.T) {
+	f := New(strings.NewReader("not a feed at all"))
+	if f.Err == nil {
+		t.Fatal("expected an error for unparseable feed content")
+	}
+}
@@ -0,0 +1,373 @@
+package html
+
+import (
+	"iter"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"weak"
+
+	"github.com/goplus/dql/stream"
+)
+
+// -----------------------------------------------------------------------------
+//
+// Per-node base URL tracking. Nodes parsed by this package all descend from a
+// single root *Node per document; rather than attaching metadata to every
+// node (which would require wrapping the public *html.Node API), we record
+// the base URL once per document root and look it up by walking a node's
+// Parent chain. The root is keyed by a weak.Pointer so a tracked document
+// doesn't outlive its last real reference, and an AddCleanup hook removes
+// the entry once the root is actually collected, so a long XGo_Follow crawl
+// doesn't accumulate every page it ever visited for the life of the process.
+
+var (
+	baseURLMu sync.RWMutex
+	baseURLs  = map[weak.Pointer[Node]]string{}
+)
+
+// setBaseURL records the base URL a document (identified by its root node)
+// was fetched from, so that XGo_Follow can resolve relative links found in it.
+func setBaseURL(root *Node, rawURL string) {
+	wp := weak.Make(root)
+	baseURLMu.Lock()
+	baseURLs[wp] = rawURL
+	baseURLMu.Unlock()
+	runtime.AddCleanup(root, func(wp weak.Pointer[Node]) {
+		baseURLMu.Lock()
+		delete(baseURLs, wp)
+		baseURLMu.Unlock()
+	}, wp)
+}
+
+// baseURLFor returns the base URL recorded for n's document root, or "" if none.
+func baseURLFor(n *Node) string {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	wp := weak.Make(root)
+	baseURLMu.RLock()
+	u := baseURLs[wp]
+	baseURLMu.RUnlock()
+	return u
+}
+
+// -----------------------------------------------------------------------------
+
+// FollowOption configures a XGo_Follow crawl.
+type FollowOption func(*followConfig)
+
+type followConfig struct {
+	maxDepth    int
+	sameHost    bool
+	concurrency int
+	rateLimit   float64
+	dedup       bool
+	userAgent   string
+}
+
+// MaxDepth limits how many hops XGo_Follow will chase from the nodes it was
+// called on. The default is 1 (follow once, without recursing into the
+// fetched pages).
+func MaxDepth(n int) FollowOption {
+	return func(c *followConfig) { c.maxDepth = n }
+}
+
+// SameHost restricts XGo_Follow to links whose host matches the page they
+// were found on.
+func SameHost(same bool) FollowOption {
+	return func(c *followConfig) { c.sameHost = same }
+}
+
+// Concurrency sets how many links XGo_Follow fetches at once. The default is 1.
+func Concurrency(n int) FollowOption {
+	return func(c *followConfig) { c.concurrency = n }
+}
+
+// RateLimit caps XGo_Follow to at most perSec fetches per second, shared
+// across all its workers.
+func RateLimit(perSec float64) FollowOption {
+	return func(c *followConfig) { c.rateLimit = perSec }
+}
+
+// Dedup skips URLs that XGo_Follow has already visited in this crawl.
+func Dedup() FollowOption {
+	return func(c *followConfig) { c.dedup = true }
+}
+
+// UserAgent sets the User-Agent header XGo_Follow sends when fetching links.
+func UserAgent(ua string) FollowOption {
+	return func(c *followConfig) { c.userAgent = ua }
+}
+
+// -----------------------------------------------------------------------------
+
+// XGo_Follow reads attr (typically "href" or "src") from each node in p,
+// resolves it against the node's document base URL, fetches the target as
+// HTML, and yields the resulting root nodes downstream. It composes
+// recursively, so a multi-hop crawl is written as a chain of
+// `.follow(attr).any.node(tag).follow(attr)` calls; a single call can also
+// recurse on its own via MaxDepth.
+func (p NodeSet) XGo_Follow(attr string, opts ...FollowOption) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	cfg := followConfig{maxDepth: 1, concurrency: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.concurrency < 1 {
+		cfg.concurrency = 1
+	}
+	return NodeSet{
+		Data: func(yield func(*Node) bool) {
+			c := &crawler{cfg: cfg, attr: attr, limiter: newRateLimiter(cfg.rateLimit), visited: map[string]bool{}}
+			c.run(p.data(), yield)
+		},
+	}
+}
+
+// crawler holds the shared state of one XGo_Follow traversal: the set of
+// already-visited URLs (when Dedup is set), the rate limiter, and the
+// bounded worker pool used to fetch links concurrently.
+type crawler struct {
+	cfg     followConfig
+	attr    string
+	limiter *rateLimiter
+
+	mu      sync.Mutex
+	visited map[string]bool
+
+	yieldMu sync.Mutex
+	stopped bool
+}
+
+// fetchTask is a resolved, dedup-checked link waiting for a worker to fetch it.
+type fetchTask struct {
+	target string
+	depth  int
+}
+
+// run fetches the link named by c.attr from each node in nodes (and,
+// recursively, from the fetched pages up to c.cfg.maxDepth), yielding every
+// fetched document's root node downstream as soon as it's ready.
+//
+// Resolved links are pushed onto a shared queue that c.cfg.concurrency
+// worker goroutines drain; a worker that fetches a page and wants to recurse
+// into its descendants pushes new tasks onto the same queue instead of
+// synchronously fetching them itself, so it never blocks waiting for a pool
+// slot it already holds.
+func (c *crawler) run(nodes iter.Seq[*Node], yield func(*Node) bool) {
+	var (
+		mu      sync.Mutex
+		cond    = sync.NewCond(&mu)
+		queue   []fetchTask
+		pending int
+		closed  bool
+	)
+
+	enqueue := func(t fetchTask) {
+		mu.Lock()
+		pending++
+		queue = append(queue, t)
+		cond.Signal()
+		mu.Unlock()
+	}
+	taskDone := func() {
+		mu.Lock()
+		pending--
+		if pending == 0 {
+			closed = true
+			cond.Broadcast()
+		}
+		mu.Unlock()
+	}
+
+	// prepare resolves n's link and, if it passes SameHost/Dedup, enqueues a
+	// fetch task for the worker pool. It does no I/O, so it's run directly by
+	// whichever goroutine discovers n - the initial scan below, or a worker
+	// walking a fetched page's descendants - rather than occupying a pool
+	// slot of its own.
+	prepare := func(n *Node, depth int) {
+		href, found := attrVal(n, c.attr)
+		if !found {
+			return
+		}
+		base := baseURLFor(n)
+		target := resolveURL(base, href)
+		if target == "" {
+			return
+		}
+		if c.cfg.sameHost && base != "" && !sameHost(base, target) {
+			return
+		}
+		if c.cfg.dedup {
+			c.mu.Lock()
+			if c.visited[target] {
+				c.mu.Unlock()
+				return
+			}
+			c.visited[target] = true
+			c.mu.Unlock()
+		}
+		enqueue(fetchTask{target, depth})
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < c.cfg.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				for len(queue) == 0 && !closed {
+					cond.Wait()
+				}
+				if len(queue) == 0 {
+					mu.Unlock()
+					return
+				}
+				t := queue[0]
+				queue = queue[1:]
+				mu.Unlock()
+
+				c.yieldMu.Lock()
+				stopped := c.stopped
+				c.yieldMu.Unlock()
+				if !stopped {
+					c.limiter.wait()
+					if root, err := fetchHTML(t.target, c.cfg.userAgent); err == nil {
+						setBaseURL(root, t.target)
+
+						c.yieldMu.Lock()
+						if !c.stopped && !yield(root) {
+							c.stopped = true
+						}
+						c.yieldMu.Unlock()
+
+						if t.depth < c.cfg.maxDepth {
+							root.Descendants()(func(child *Node) bool {
+								prepare(child, t.depth+1)
+								return true
+							})
+						}
+					}
+				}
+				taskDone()
+			}
+		}()
+	}
+
+	nodes(func(n *Node) bool {
+		c.yieldMu.Lock()
+		stopped := c.stopped
+		c.yieldMu.Unlock()
+		if stopped {
+			return false
+		}
+		prepare(n, 1)
+		return true
+	})
+
+	mu.Lock()
+	if pending == 0 {
+		closed = true
+		cond.Broadcast()
+	}
+	mu.Unlock()
+
+	wg.Wait()
+}
+
+// -----------------------------------------------------------------------------
+
+type rateLimiter struct {
+	mu       sync.Mutex
+	last     time.Time
+	interval time.Duration
+}
+
+func newRateLimiter(perSec float64) *rateLimiter {
+	if perSec <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSec)}
+}
+
+func (r *rateLimiter) wait() {
+	if r.interval == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if !r.last.IsZero() {
+		if d := r.last.Add(r.interval).Sub(now); d > 0 {
+			time.Sleep(d)
+			now = now.Add(d)
+		}
+	}
+	r.last = now
+}
+
+// -----------------------------------------------------------------------------
+
+func resolveURL(base, ref string) string {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base == "" {
+		if !refURL.IsAbs() {
+			return ""
+		}
+		return refURL.String()
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+func sameHost(a, b string) bool {
+	au, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	bu, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return au.Host == bu.Host
+}
+
+func fetchHTML(target, userAgent string) (*Node, error) {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		req, err := http.NewRequest(http.MethodGet, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		if userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return newDoc(resp.Body)
+	}
+	f, err := stream.Open(target)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return newDoc(f)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,105 @@
+package html
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// followTestServer serves a root page linking to n leaf pages (/page/0 ...
+// /page/n-1), each of which links to a single page /page/N/more one level
+// deeper. fetched counts how many requests actually reached the server.
+func followTestServer(t *testing.T, n int, fetched *int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(fetched, 1)
+		var b strings.Builder
+		b.WriteString("<html><body>")
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, `<a href="/page/%d">p%d</a>`, i, i)
+		}
+		b.WriteString("</body></html>")
+		w.Write([]byte(b.String()))
+	})
+	for i := 0; i < n; i++ {
+		i := i
+		mux.HandleFunc(fmt.Sprintf("/page/%d", i), func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(fetched, 1)
+			fmt.Fprintf(w, `<html><body><a href="/page/%d/more">more</a></body></html>`, i)
+		})
+		mux.HandleFunc(fmt.Sprintf("/page/%d/more", i), func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(fetched, 1)
+			w.Write([]byte(`<html><body>leaf</body></html>`))
+		})
+	}
+	return httptest.NewServer(mux)
+}
+
+func rootNodeSet(t *testing.T, url string) NodeSet {
+	t.Helper()
+	root := Source(url)
+	if root.Err != nil {
+		t.Fatalf("Source(%q) error: %v", url, root.Err)
+	}
+	return root
+}
+
+func TestFollowDoesNotDeadlockAtMaxDepth(t *testing.T) {
+	var fetched int32
+	srv := followTestServer(t, 3, &fetched)
+	defer srv.Close()
+
+	root := rootNodeSet(t, srv.URL)
+
+	done := make(chan struct{})
+	var leaves int
+	go func() {
+		root.XGo_Node("html").XGo_Node("body").XGo_Node("a").
+			XGo_Follow("href", MaxDepth(2), Concurrency(2)).XGo_Enum()(func(n *Node) bool {
+			leaves++
+			return true
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("XGo_Follow(MaxDepth(2)) deadlocked")
+	}
+	// 3 first-hop pages + 3 second-hop pages = 6 yielded documents (the root
+	// page is fetched by Source, not yielded by XGo_Follow).
+	if leaves != 6 {
+		t.Errorf("XGo_Follow yielded %d documents, want 6", leaves)
+	}
+}
+
+func TestFollowStopsEarly(t *testing.T) {
+	var fetched int32
+	srv := followTestServer(t, 50, &fetched)
+	defer srv.Close()
+
+	root := rootNodeSet(t, srv.URL)
+
+	done := make(chan struct{})
+	go func() {
+		root.XGo_Node("html").XGo_Node("body").XGo_Node("a").XGo_Follow("href").XGo_Enum()(func(n *Node) bool {
+			return false
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("XGo_Follow did not return promptly after the consumer stopped early")
+	}
+	if got := atomic.LoadInt32(&fetched); got >= 50 {
+		t.Errorf("fetched %d pages after stopping on the first result, want well under 50", got)
+	}
+}
@@ -2,11 +2,13 @@ package html
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"iter"
 
 	"github.com/goplus/dql/stream"
+	"github.com/goplus/dql/util"
 	"golang.org/x/net/html"
 )
 
@@ -82,13 +84,60 @@ type Node = html.Node
 type NodeSet struct {
 	Data iter.Seq[*Node]
 	Err  error
+
+	parallel util.ParallelConfig
+}
+
+// data returns the node sequence with any pending XGo_Parallel/XGo_Ordered/
+// XGo_WithContext configuration materialized.
+func (p NodeSet) data() iter.Seq[*Node] {
+	return util.Parallelize(p.Data, p.parallel)
+}
+
+// XGo_Parallel returns a NodeSet that pulls from p through a worker pool of n
+// goroutines, so that the per-node work of the next operator (XGo_Node,
+// XGo_Child, XGo_Any, XGo_Attr, XGo_Select) runs concurrently over its nodes;
+// XGo_Enum, XGo_0, XGo_1, XGo_Match, XGo_XPath and XGo_Follow only relay the
+// nodes through the pool. Chain XGo_Ordered to preserve p's original
+// order, and XGo_WithContext to bind a context whose cancellation shuts the
+// pool down.
+func (p NodeSet) XGo_Parallel(n int) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	p.parallel.N = n
+	return p
+}
+
+// XGo_Ordered requests that a chained XGo_Parallel preserve p's original order.
+func (p NodeSet) XGo_Ordered() NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	p.parallel.Ordered = true
+	return p
+}
+
+// XGo_WithContext binds ctx to a chained XGo_Parallel, so that cancelling ctx
+// stops the dispatcher and worker goroutines.
+func (p NodeSet) XGo_WithContext(ctx context.Context) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	p.parallel.Ctx = ctx
+	return p
+}
+
+// newDoc parses the HTML document from r and returns its root node.
+func newDoc(r io.Reader) (*Node, error) {
+	return html.Parse(r)
 }
 
 // New parses the HTML document from the provided reader and returns a NodeSet
 // containing the root node. If there is an error during parsing, the NodeSet's
 // Err field is set.
 func New(r io.Reader) NodeSet {
-	doc, err := html.Parse(r)
+	doc, err := newDoc(r)
 	if err != nil {
 		return NodeSet{Err: err}
 	}
@@ -106,6 +155,9 @@ func New(r io.Reader) NodeSet {
 // - iter.Seq[*Node]: directly uses the provided sequence of nodes.
 // - NodeSet: returns the provided NodeSet as is.
 // If the source type is unsupported, it panics.
+//
+// When r is a string, the fetched document's root node is tagged with that
+// URL as its base, so that a later XGo_Follow can resolve relative links.
 func Source(r any) (ret NodeSet) {
 	switch v := r.(type) {
 	case string:
@@ -114,7 +166,16 @@ func Source(r any) (ret NodeSet) {
 			return NodeSet{Err: err}
 		}
 		defer f.Close()
-		return New(f)
+		doc, err := newDoc(f)
+		if err != nil {
+			return NodeSet{Err: err}
+		}
+		setBaseURL(doc, v)
+		return NodeSet{
+			Data: func(yield func(*Node) bool) {
+				yield(doc)
+			},
+		}
 	case []byte:
 		r := bytes.NewReader(v)
 		return New(r)
@@ -134,23 +195,28 @@ func (p NodeSet) XGo_Enum() iter.Seq[*Node] {
 	if p.Err != nil {
 		return nopIter[*Node]
 	}
-	return p.Data
+	return p.data()
 }
 
-// XGo_Node returns a NodeSet containing the child nodes with the specified name.
+// XGo_Node returns a NodeSet containing the child nodes with the specified
+// name. The match test itself runs inside a chained XGo_Parallel's worker
+// pool, so it's the per-node scan that's distributed, not just the relaying
+// of already-pulled nodes.
 func (p NodeSet) XGo_Node(name string) NodeSet {
 	if p.Err != nil {
 		return p
 	}
 	return NodeSet{
-		Data: func(yield func(*Node) bool) {
-			p.Data(func(node *Node) bool {
-				if node.Type == html.ElementNode && node.Data == name {
-					return yield(node)
+		Data: util.ParallelExpand(p.Data, p.parallel, func(node *Node, yield func(*Node) bool) bool {
+			ok := true
+			node.ChildNodes()(func(c *Node) bool {
+				if c.Type == html.ElementNode && c.Data == name {
+					ok = yield(c)
 				}
-				return true
+				return ok
 			})
-		},
+			return ok
+		}),
 	}
 }
 
@@ -160,16 +226,14 @@ func (p NodeSet) XGo_Child() NodeSet {
 		return p
 	}
 	return NodeSet{
-		Data: func(yield func(*Node) bool) {
+		Data: util.ParallelExpand(p.Data, p.parallel, func(node *Node, yield func(*Node) bool) bool {
 			ok := true
-			p.Data(func(node *Node) bool {
-				node.ChildNodes()(func(c *Node) bool {
-					ok = yield(c)
-					return ok
-				})
+			node.ChildNodes()(func(c *Node) bool {
+				ok = yield(c)
 				return ok
 			})
-		},
+			return ok
+		}),
 	}
 }
 
@@ -180,40 +244,36 @@ func (p NodeSet) XGo_Any() NodeSet {
 		return p
 	}
 	return NodeSet{
-		Data: func(yield func(*Node) bool) {
-			ok := true
-			p.Data(func(node *Node) bool {
-				if ok = yield(node); ok {
-					node.Descendants()(func(c *Node) bool {
-						ok = yield(c)
-						return ok
-					})
-				}
-				return ok
-			})
-		},
+		Data: util.ParallelExpand(p.Data, p.parallel, func(node *Node, yield func(*Node) bool) bool {
+			ok := yield(node)
+			if ok {
+				node.Descendants()(func(c *Node) bool {
+					ok = yield(c)
+					return ok
+				})
+			}
+			return ok
+		}),
 	}
 }
 
 // XGo_Attr returns a ValueSet containing the values of the specified attribute
 // for each node in the NodeSet. If a node does not have the specified attribute,
-// the Value will contain ErrNotFound.
+// the Value will contain ErrNotFound. The lookup itself runs inside a chained
+// XGo_Parallel's worker pool.
 func (p NodeSet) XGo_Attr(name string) ValueSet {
 	if p.Err != nil {
 		return ValueSet{Err: p.Err}
 	}
 	return ValueSet{
-		Data: func(yield func(Value) bool) {
-			p.Data(func(node *Node) bool {
-				for _, attr := range node.Attr {
-					if attr.Key == name {
-						return yield(Value{X_0: attr.Val})
-					}
+		Data: util.ParallelMap(p.Data, p.parallel, func(node *Node) Value {
+			for _, attr := range node.Attr {
+				if attr.Key == name {
+					return Value{X_0: attr.Val}
 				}
-				yield(Value{X_1: ErrNotFound})
-				return true
-			})
-		},
+			}
+			return Value{X_1: ErrNotFound}
+		}),
 	}
 }
 
@@ -223,7 +283,7 @@ func (p NodeSet) XGo_0() (val *Node, err error) {
 		return nil, p.Err
 	}
 	err = ErrNotFound
-	p.Data(func(n *Node) bool {
+	p.data()(func(n *Node) bool {
 		val, err = n, nil
 		return false
 	})
@@ -238,7 +298,7 @@ func (p NodeSet) XGo_1() (val *Node, err error) {
 	}
 	first := true
 	err = ErrNotFound
-	p.Data(func(n *Node) bool {
+	p.data()(func(n *Node) bool {
 		if first {
 			val, err = n, nil
 			first = false
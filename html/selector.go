@@ -0,0 +1,613 @@
+package html
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goplus/dql/util"
+	"golang.org/x/net/html"
+)
+
+// -----------------------------------------------------------------------------
+
+// selector is a compiled CSS selector list: the node matches if it matches
+// any of the alternatives (comma-separated selectors in the source text).
+type selector struct {
+	alts []selectorChain
+}
+
+// selectorChain is a single compound-selector chain, rightmost step last.
+// chain[0] has an empty combinator; chain[i>0].combinator links it to
+// chain[i-1] (its left-hand side).
+type selectorChain []selectorStep
+
+type selectorStep struct {
+	combinator byte // 0, ' ' (descendant), '>' (child), '+' (adjacent), '~' (sibling)
+	simples    []simpleMatcher
+}
+
+// simpleMatcher tests a single simple selector (type, #id, .class, [attr],
+// or a pseudo-class) against a node.
+type simpleMatcher func(n *Node) bool
+
+// compileSelector parses a CSS3 selector list into a selector.
+func compileSelector(src string) (*selector, error) {
+	p := &selectorParser{s: src}
+	sel, err := p.parseSelectorList()
+	if err != nil {
+		return nil, fmt.Errorf("dql/html: invalid selector %q: %w", src, err)
+	}
+	return sel, nil
+}
+
+// match reports whether n matches any alternative of the selector.
+func (s *selector) match(n *Node) bool {
+	for _, chain := range s.alts {
+		if matchChain(n, chain) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchChain matches the rightmost step against n, then walks left through
+// the combinators against n's real ancestors/siblings in the DOM tree.
+func matchChain(n *Node, chain selectorChain) bool {
+	if n == nil || len(chain) == 0 {
+		return false
+	}
+	last := chain[len(chain)-1]
+	if !matchSimples(n, last.simples) {
+		return false
+	}
+	return matchRest(n, chain)
+}
+
+// matchRest is called with n already matching chain's last step; that step's
+// combinator says how n relates to the step on its left.
+func matchRest(n *Node, chain selectorChain) bool {
+	if len(chain) < 2 {
+		return true
+	}
+	comb := chain[len(chain)-1].combinator
+	rest := chain[:len(chain)-1]
+	step := rest[len(rest)-1]
+	switch comb {
+	case '>':
+		p := n.Parent
+		return p != nil && matchSimples(p, step.simples) && matchRest(p, rest)
+	case '+':
+		p := prevElementSibling(n)
+		return p != nil && matchSimples(p, step.simples) && matchRest(p, rest)
+	case '~':
+		for p := prevElementSibling(n); p != nil; p = prevElementSibling(p) {
+			if matchSimples(p, step.simples) && matchRest(p, rest) {
+				return true
+			}
+		}
+		return false
+	default: // descendant
+		for p := n.Parent; p != nil; p = p.Parent {
+			if p.Type != html.ElementNode {
+				continue
+			}
+			if matchSimples(p, step.simples) && matchRest(p, rest) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func matchSimples(n *Node, simples []simpleMatcher) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	for _, m := range simples {
+		if !m(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func prevElementSibling(n *Node) *Node {
+	for p := n.PrevSibling; p != nil; p = p.PrevSibling {
+		if p.Type == html.ElementNode {
+			return p
+		}
+	}
+	return nil
+}
+
+func nextElementSibling(n *Node) *Node {
+	for p := n.NextSibling; p != nil; p = p.NextSibling {
+		if p.Type == html.ElementNode {
+			return p
+		}
+	}
+	return nil
+}
+
+func attrVal(n *Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// -----------------------------------------------------------------------------
+
+// selectorParser is a small recursive-descent parser for CSS3 selectors.
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) parseSelectorList() (*selector, error) {
+	sel := &selector{}
+	for {
+		p.skipSpace()
+		chain, err := p.parseChain()
+		if err != nil {
+			return nil, err
+		}
+		sel.alts = append(sel.alts, chain)
+		p.skipSpace()
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.s[p.pos:], p.pos)
+	}
+	return sel, nil
+}
+
+func (p *selectorParser) parseChain() (selectorChain, error) {
+	var chain selectorChain
+	var comb byte
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.peek() == ',' {
+			break
+		}
+		simples, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, selectorStep{combinator: comb, simples: simples})
+		comb = 0
+		hadSpace := p.skipSpace()
+		if p.pos >= len(p.s) || p.peek() == ',' {
+			break
+		}
+		switch p.peek() {
+		case '>', '+', '~':
+			comb = p.s[p.pos]
+			p.pos++
+			p.skipSpace()
+		default:
+			if !hadSpace {
+				return nil, fmt.Errorf("expected combinator at %d", p.pos)
+			}
+			comb = ' '
+		}
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+	return chain, nil
+}
+
+func (p *selectorParser) parseCompound() ([]simpleMatcher, error) {
+	var simples []simpleMatcher
+	matchedAny := false
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == '*':
+			p.pos++
+			matchedAny = true
+		case isNameStart(c):
+			name := p.parseName()
+			simples = append(simples, tagMatcher(name))
+			matchedAny = true
+		case c == '#':
+			p.pos++
+			name := p.parseName()
+			simples = append(simples, idMatcher(name))
+			matchedAny = true
+		case c == '.':
+			p.pos++
+			name := p.parseName()
+			simples = append(simples, classMatcher(name))
+			matchedAny = true
+		case c == '[':
+			m, err := p.parseAttr()
+			if err != nil {
+				return nil, err
+			}
+			simples = append(simples, m)
+			matchedAny = true
+		case c == ':':
+			m, err := p.parsePseudo()
+			if err != nil {
+				return nil, err
+			}
+			simples = append(simples, m)
+			matchedAny = true
+		default:
+			if !matchedAny {
+				return nil, fmt.Errorf("unexpected %q at %d", string(c), p.pos)
+			}
+			return simples, nil
+		}
+	}
+	if !matchedAny {
+		return nil, fmt.Errorf("unexpected end of selector")
+	}
+	return simples, nil
+}
+
+func (p *selectorParser) parseAttr() (simpleMatcher, error) {
+	p.pos++ // '['
+	p.skipSpace()
+	name := p.parseName()
+	if name == "" {
+		return nil, fmt.Errorf("expected attribute name at %d", p.pos)
+	}
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return attrExistsMatcher(name), nil
+	}
+	op := ""
+	switch p.peek() {
+	case '=':
+		op = "="
+		p.pos++
+	case '~', '|', '^', '$', '*':
+		op = string(p.s[p.pos]) + "="
+		p.pos += 2
+	default:
+		return nil, fmt.Errorf("expected attribute operator at %d", p.pos)
+	}
+	p.skipSpace()
+	val, err := p.parseAttrValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return nil, fmt.Errorf("expected ']' at %d", p.pos)
+	}
+	p.pos++
+	return attrOpMatcher(name, op, val), nil
+}
+
+func (p *selectorParser) parseAttrValue() (string, error) {
+	if p.pos < len(p.s) && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+		q := p.s[p.pos]
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.s) && p.s[p.pos] != q {
+			p.pos++
+		}
+		if p.pos >= len(p.s) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		val := p.s[start:p.pos]
+		p.pos++
+		return val, nil
+	}
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != ']' && p.s[p.pos] != ' ' {
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *selectorParser) parsePseudo() (simpleMatcher, error) {
+	p.pos++ // ':'
+	name := p.parseName()
+	switch name {
+	case "first-child":
+		return func(n *Node) bool { return prevElementSibling(n) == nil }, nil
+	case "last-child":
+		return func(n *Node) bool { return nextElementSibling(n) == nil }, nil
+	case "empty":
+		return func(n *Node) bool {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode || (c.Type == html.TextNode && c.Data != "") {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case "not":
+		inner, err := p.parseArgSelector()
+		if err != nil {
+			return nil, err
+		}
+		return func(n *Node) bool { return !inner.match(n) }, nil
+	case "has":
+		inner, err := p.parseArgSelector()
+		if err != nil {
+			return nil, err
+		}
+		return func(n *Node) bool {
+			found := false
+			forEachDescendant(n, func(c *Node) bool {
+				if inner.match(c) {
+					found = true
+					return false
+				}
+				return true
+			})
+			return found
+		}, nil
+	case "nth-child":
+		expr, err := p.parseArgRaw()
+		if err != nil {
+			return nil, err
+		}
+		a, b, err := parseNth(expr)
+		if err != nil {
+			return nil, err
+		}
+		return func(n *Node) bool {
+			idx := 1
+			for p := prevElementSibling(n); p != nil; p = prevElementSibling(p) {
+				idx++
+			}
+			return nthMatches(idx, a, b)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported pseudo-class %q", name)
+	}
+}
+
+// parseArgSelector parses a parenthesised selector list, e.g. "(a.b, c)".
+func (p *selectorParser) parseArgSelector() (*selector, error) {
+	raw, err := p.parseArgRaw()
+	if err != nil {
+		return nil, err
+	}
+	inner := &selectorParser{s: raw}
+	return inner.parseSelectorList()
+}
+
+// parseArgRaw consumes a balanced "(...)" group and returns its contents.
+func (p *selectorParser) parseArgRaw() (string, error) {
+	if p.peek() != '(' {
+		return "", fmt.Errorf("expected '(' at %d", p.pos)
+	}
+	p.pos++
+	start := p.pos
+	depth := 1
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				raw := p.s[start:p.pos]
+				p.pos++
+				return strings.TrimSpace(raw), nil
+			}
+		}
+		p.pos++
+	}
+	return "", fmt.Errorf("unterminated %q", "(")
+}
+
+func (p *selectorParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.s) && isNameChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *selectorParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+func forEachDescendant(n *Node, f func(*Node) bool) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			if !f(c) {
+				return false
+			}
+		}
+		if !forEachDescendant(c, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// -----------------------------------------------------------------------------
+
+func tagMatcher(name string) simpleMatcher {
+	return func(n *Node) bool { return n.Data == name }
+}
+
+func idMatcher(id string) simpleMatcher {
+	return func(n *Node) bool {
+		v, ok := attrVal(n, "id")
+		return ok && v == id
+	}
+}
+
+func classMatcher(class string) simpleMatcher {
+	return func(n *Node) bool {
+		v, ok := attrVal(n, "class")
+		if !ok {
+			return false
+		}
+		for _, c := range strings.Fields(v) {
+			if c == class {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func attrExistsMatcher(name string) simpleMatcher {
+	return func(n *Node) bool {
+		_, ok := attrVal(n, name)
+		return ok
+	}
+}
+
+func attrOpMatcher(name, op, want string) simpleMatcher {
+	return func(n *Node) bool {
+		v, ok := attrVal(n, name)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "=":
+			return v == want
+		case "~=":
+			for _, w := range strings.Fields(v) {
+				if w == want {
+					return true
+				}
+			}
+			return false
+		case "|=":
+			return v == want || strings.HasPrefix(v, want+"-")
+		case "^=":
+			return want != "" && strings.HasPrefix(v, want)
+		case "$=":
+			return want != "" && strings.HasSuffix(v, want)
+		case "*=":
+			return want != "" && strings.Contains(v, want)
+		default:
+			return false
+		}
+	}
+}
+
+// parseNth parses an :nth-child argument ("odd", "even", "<a>n+<b>", or an
+// integer) into its a*n+b coefficients.
+func parseNth(expr string) (a, b int, err error) {
+	expr = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(expr, " ", "")))
+	switch expr {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+	if i := strings.IndexByte(expr, 'n'); i >= 0 {
+		aPart := expr[:i]
+		switch aPart {
+		case "", "+":
+			a = 1
+		case "-":
+			a = -1
+		default:
+			a, err = strconv.Atoi(aPart)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+		bPart := expr[i+1:]
+		if bPart == "" {
+			b = 0
+		} else {
+			b, err = strconv.Atoi(bPart)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+		return a, b, nil
+	}
+	b, err = strconv.Atoi(expr)
+	return 0, b, err
+}
+
+func nthMatches(idx, a, b int) bool {
+	if a == 0 {
+		return idx == b
+	}
+	d := idx - b
+	return d%a == 0 && d/a >= 0
+}
+
+// -----------------------------------------------------------------------------
+
+// XGo_Select returns a NodeSet containing the nodes in p that match the given
+// CSS3 selector (e.g. `div.article > a[href^="/"]`). The selector is compiled
+// once; matching is then streamed lazily over the existing node sequence.
+// Compilation errors are attached to the result's Err, short-circuiting any
+// chained operators.
+func (p NodeSet) XGo_Select(sel string) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	compiled, err := compileSelector(sel)
+	if err != nil {
+		return NodeSet{Err: err}
+	}
+	return NodeSet{
+		Data: util.ParallelFilter(p.Data, p.parallel, compiled.match),
+	}
+}
+
+// XGo_Find is an alias for XGo_Select, matching the common goquery/cascadia naming.
+func (p NodeSet) XGo_Find(sel string) NodeSet {
+	return p.XGo_Select(sel)
+}
+
+// XGo_Match reports whether at least one node in p matches the given CSS3 selector.
+func (p NodeSet) XGo_Match(sel string) (bool, error) {
+	if p.Err != nil {
+		return false, p.Err
+	}
+	compiled, err := compileSelector(sel)
+	if err != nil {
+		return false, err
+	}
+	found := false
+	p.data()(func(n *Node) bool {
+		if compiled.match(n) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found, nil
+}
+
+// -----------------------------------------------------------------------------
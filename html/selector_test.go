@@ -0,0 +1,101 @@
+package html
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+const selectorTestHTML = `<html><body>
+<div id="main" data-t="main">
+  <p class="item" data-t="p1">one</p>
+  <p class="item skip" data-t="p2">two</p>
+  <ul>
+    <li data-t="li1">a</li>
+    <li data-t="li2">b</li>
+    <li data-t="li3">c</li>
+    <li data-t="li4">d</li>
+  </ul>
+  <h1 data-t="h1">Head</h1>
+  <p data-t="p3">after h1</p>
+  <p data-t="haslink"><a data-t="a1" href="/articles/1" class="nav primary">A1</a></p>
+  <a data-t="a2" href="https://example.com/x" class="nav">A2</a>
+  <a data-t="a3" class="primary">A3</a>
+  <span data-t="span1">x</span>
+  <div class="wrap" data-t="wrap"><p data-t="pdeep">deep</p></div>
+</div>
+<div id="other" data-t="other">
+  <p data-t="p4">other</p>
+</div>
+<div class="empty" data-t="emptydiv"></div>
+</body></html>`
+
+// dataTags collects the sorted "data-t" markers of every node in ns.
+func dataTags(ns NodeSet) []string {
+	var got []string
+	ns.XGo_Attr("data-t").XGo_Enum()(func(v Value) bool {
+		if v.X_1 == nil {
+			got = append(got, v.X_0)
+		}
+		return true
+	})
+	sort.Strings(got)
+	return got
+}
+
+func TestSelectorMatch(t *testing.T) {
+	doc := New(strings.NewReader(selectorTestHTML))
+	all := doc.XGo_Any()
+
+	tests := []struct {
+		name string
+		sel  string
+		want []string
+	}{
+		{"tag", "p", []string{"haslink", "p1", "p2", "p3", "p4", "pdeep"}},
+		{"id", "#main", []string{"main"}},
+		{"class", ".item", []string{"p1", "p2"}},
+		{"not", ".item:not(.skip)", []string{"p1"}},
+		{"descendant", "#main p", []string{"haslink", "p1", "p2", "p3", "pdeep"}},
+		{"child", "#main > p", []string{"haslink", "p1", "p2", "p3"}},
+		{"adjacent-sibling", "h1 + p", []string{"p3"}},
+		{"general-sibling", "h1 ~ p", []string{"haslink", "p3"}},
+		{"attr-exists", "[href]", []string{"a1", "a2"}},
+		{"attr-prefix", "[href^='/']", []string{"a1"}},
+		{"attr-suffix", "[href$='1']", []string{"a1"}},
+		{"attr-substring", "[href*='example']", []string{"a2"}},
+		{"attr-word", "[class~='nav']", []string{"a1", "a2"}},
+		{"attr-hyphen", "[class|='primary']", []string{"a3"}},
+		{"nth-child-index", "li:nth-child(2)", []string{"li2"}},
+		{"nth-child-odd", "li:nth-child(odd)", []string{"li1", "li3"}},
+		{"nth-child-formula", "li:nth-child(2n+1)", []string{"li1", "li3"}},
+		{"first-child", "li:first-child", []string{"li1"}},
+		{"last-child", "li:last-child", []string{"li4"}},
+		{"empty", "div:empty", []string{"emptydiv"}},
+		{"has", "p:has(a)", []string{"haslink"}},
+		{"list", "h1, span", []string{"h1", "span1"}},
+		{"three-step-chain", "#main ul li:first-child", []string{"li1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := all.XGo_Select(tt.sel)
+			if ns.Err != nil {
+				t.Fatalf("XGo_Select(%q) error: %v", tt.sel, ns.Err)
+			}
+			got := dataTags(ns)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("XGo_Select(%q) = %v, want %v", tt.sel, got, want)
+			}
+		})
+	}
+}
+
+func TestSelectorMatchInvalid(t *testing.T) {
+	doc := New(strings.NewReader(selectorTestHTML))
+	ns := doc.XGo_Any().XGo_Select("div[")
+	if ns.Err == nil {
+		t.Fatal("expected an error for an unterminated attribute selector")
+	}
+}
@@ -0,0 +1,888 @@
+package html
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// -----------------------------------------------------------------------------
+//
+// A small XPath 1.0 engine covering the core axes, node tests, predicates and
+// string/number functions most DQL queries need. Expressions are compiled to
+// an AST once and cached in an LRU keyed by the source text, then evaluated
+// lazily against the *html.Node tree.
+
+// xpathExpr is a compiled XPath location path: a sequence of steps applied
+// left to right, each relative to the node set produced by the previous one.
+type xpathExpr struct {
+	steps []xpathStep
+}
+
+type xpathAxis int
+
+const (
+	axisChild xpathAxis = iota
+	axisDescendant
+	axisDescendantOrSelf
+	axisParent
+	axisAncestor
+	axisSelf
+	axisFollowingSibling
+	axisPrecedingSibling
+)
+
+type xpathStep struct {
+	axis       xpathAxis
+	name       string // "*" for any element, "" for node tests below
+	textTest   bool   // text()
+	predicates []xpathPred
+}
+
+// xpathPred is a compiled predicate expression: given a candidate node, its
+// position among siblings produced by the step, and the total count, it
+// reports whether the node survives the predicate.
+type xpathPred func(n *Node, pos, size int) bool
+
+// -----------------------------------------------------------------------------
+
+// xpathCacheEntry is the value stored in xpathCacheLRU's list elements, kept
+// alongside its own key so an evicted element (taken from the back of the
+// list) can delete itself from xpathCacheIndex.
+type xpathCacheEntry struct {
+	expr     string
+	compiled *xpathExpr
+}
+
+var (
+	xpathCacheMu    sync.Mutex
+	xpathCacheIndex = map[string]*list.Element{}
+	xpathCacheLRU   = list.New() // most recently used at the front
+)
+
+const xpathCacheLimit = 256
+
+func compileXPathCached(expr string) (*xpathExpr, error) {
+	xpathCacheMu.Lock()
+	if el, ok := xpathCacheIndex[expr]; ok {
+		xpathCacheLRU.MoveToFront(el)
+		e := el.Value.(*xpathCacheEntry).compiled
+		xpathCacheMu.Unlock()
+		return e, nil
+	}
+	xpathCacheMu.Unlock()
+
+	e, err := compileXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	xpathCacheMu.Lock()
+	defer xpathCacheMu.Unlock()
+	if el, ok := xpathCacheIndex[expr]; ok {
+		xpathCacheLRU.MoveToFront(el)
+		return el.Value.(*xpathCacheEntry).compiled, nil
+	}
+	if xpathCacheLRU.Len() >= xpathCacheLimit {
+		if oldest := xpathCacheLRU.Back(); oldest != nil {
+			xpathCacheLRU.Remove(oldest)
+			delete(xpathCacheIndex, oldest.Value.(*xpathCacheEntry).expr)
+		}
+	}
+	xpathCacheIndex[expr] = xpathCacheLRU.PushFront(&xpathCacheEntry{expr: expr, compiled: e})
+	return e, nil
+}
+
+// compileXPath parses an XPath 1.0 location path into an xpathExpr.
+func compileXPath(expr string) (*xpathExpr, error) {
+	p := &xpathParser{s: strings.TrimSpace(expr)}
+	e, err := p.parsePath()
+	if err != nil {
+		return nil, fmt.Errorf("dql/html: invalid xpath %q: %w", expr, err)
+	}
+	return e, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type xpathParser struct {
+	s   string
+	pos int
+}
+
+func (p *xpathParser) parsePath() (*xpathExpr, error) {
+	e := &xpathExpr{}
+	if strings.HasPrefix(p.s[p.pos:], "//") {
+		p.pos += 2
+		e.steps = append(e.steps, xpathStep{axis: axisDescendantOrSelf, name: "*"})
+	} else if strings.HasPrefix(p.s[p.pos:], "/") {
+		p.pos++
+	}
+	for p.pos < len(p.s) {
+		step, err := p.parseStep()
+		if err != nil {
+			return nil, err
+		}
+		e.steps = append(e.steps, step)
+		if p.pos >= len(p.s) {
+			break
+		}
+		if strings.HasPrefix(p.s[p.pos:], "//") {
+			p.pos += 2
+			e.steps = append(e.steps, xpathStep{axis: axisDescendantOrSelf, name: "*"})
+		} else if p.s[p.pos] == '/' {
+			p.pos++
+		} else {
+			return nil, fmt.Errorf("unexpected %q at %d", p.s[p.pos:], p.pos)
+		}
+	}
+	if len(e.steps) == 0 {
+		e.steps = append(e.steps, xpathStep{axis: axisSelf, name: "*"})
+	}
+	return e, nil
+}
+
+func (p *xpathParser) parseStep() (xpathStep, error) {
+	axis := axisChild
+	switch {
+	case strings.HasPrefix(p.s[p.pos:], "ancestor::"):
+		axis, p.pos = axisAncestor, p.pos+len("ancestor::")
+	case strings.HasPrefix(p.s[p.pos:], "descendant-or-self::"):
+		axis, p.pos = axisDescendantOrSelf, p.pos+len("descendant-or-self::")
+	case strings.HasPrefix(p.s[p.pos:], "descendant::"):
+		axis, p.pos = axisDescendant, p.pos+len("descendant::")
+	case strings.HasPrefix(p.s[p.pos:], "following-sibling::"):
+		axis, p.pos = axisFollowingSibling, p.pos+len("following-sibling::")
+	case strings.HasPrefix(p.s[p.pos:], "preceding-sibling::"):
+		axis, p.pos = axisPrecedingSibling, p.pos+len("preceding-sibling::")
+	case strings.HasPrefix(p.s[p.pos:], "parent::"):
+		axis, p.pos = axisParent, p.pos+len("parent::")
+	case strings.HasPrefix(p.s[p.pos:], "self::"):
+		axis, p.pos = axisSelf, p.pos+len("self::")
+	case strings.HasPrefix(p.s[p.pos:], "child::"):
+		axis, p.pos = axisChild, p.pos+len("child::")
+	case strings.HasPrefix(p.s[p.pos:], ".."):
+		p.pos += 2
+		return xpathStep{axis: axisParent, name: "*"}, nil
+	case strings.HasPrefix(p.s[p.pos:], "."):
+		p.pos++
+		return xpathStep{axis: axisSelf, name: "*"}, nil
+	}
+
+	step := xpathStep{axis: axis}
+	if strings.HasPrefix(p.s[p.pos:], "text()") {
+		p.pos += len("text()")
+		step.textTest = true
+	} else if strings.HasPrefix(p.s[p.pos:], "*") {
+		p.pos++
+		step.name = "*"
+	} else if strings.HasPrefix(p.s[p.pos:], "node()") {
+		p.pos += len("node()")
+		step.name = "*"
+	} else {
+		name := p.parseName()
+		if name == "" {
+			return xpathStep{}, fmt.Errorf("expected node test at %d", p.pos)
+		}
+		step.name = name
+	}
+
+	for p.pos < len(p.s) && p.s[p.pos] == '[' {
+		pred, err := p.parsePredicate()
+		if err != nil {
+			return xpathStep{}, err
+		}
+		step.predicates = append(step.predicates, pred)
+	}
+	return step, nil
+}
+
+func (p *xpathParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.s) && isXPathNameChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func isXPathNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *xpathParser) parsePredicate() (xpathPred, error) {
+	p.pos++ // '['
+	start := p.pos
+	depth := 1
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				raw := p.s[start:p.pos]
+				p.pos++
+				return compilePredicate(raw)
+			}
+		}
+		p.pos++
+	}
+	return nil, fmt.Errorf("unterminated predicate")
+}
+
+// -----------------------------------------------------------------------------
+//
+// Predicates are evaluated by a tiny boolean/string/number expression
+// evaluator supporting the subset of XPath 1.0 used in practice: positional
+// predicates, and/or/not(), comparisons, and the core string functions.
+
+func compilePredicate(src string) (xpathPred, error) {
+	src = strings.TrimSpace(src)
+	if n, err := strconv.Atoi(src); err == nil {
+		return func(_ *Node, pos, _ int) bool { return pos == n }, nil
+	}
+	ep := &exprParser{s: src}
+	node, err := ep.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	ep.skipSpace()
+	if ep.pos != len(ep.s) {
+		return nil, fmt.Errorf("unexpected %q at %d", ep.s[ep.pos:], ep.pos)
+	}
+	return func(n *Node, pos, size int) bool {
+		return truthy(node.eval(n, pos, size))
+	}, nil
+}
+
+// exprNode is a node of the predicate expression tree. eval returns either a
+// bool, float64, or string, matching XPath's dynamic typing.
+type exprNode interface {
+	eval(n *Node, pos, size int) any
+}
+
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *exprParser) consumeWord(w string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.s[p.pos:], w) {
+		after := p.pos + len(w)
+		if after == len(p.s) || !isXPathNameChar(p.s[after]) {
+			p.pos = after
+			return true
+		}
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeWord("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = boolFunc(func(n *Node, pos, size int) bool {
+			return truthy(l.eval(n, pos, size)) || truthy(r.eval(n, pos, size))
+		})
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeWord("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = boolFunc(func(n *Node, pos, size int) bool {
+			return truthy(l.eval(n, pos, size)) && truthy(r.eval(n, pos, size))
+		})
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.consumeWord("not") {
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != '(' {
+			return nil, fmt.Errorf("expected '(' after not at %d", p.pos)
+		}
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, fmt.Errorf("expected ')' at %d", p.pos)
+		}
+		p.pos++
+		return boolFunc(func(n *Node, pos, size int) bool { return !truthy(inner.eval(n, pos, size)) }), nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	ops := []string{"<=", ">=", "!=", "=", "<", ">"}
+	for _, op := range ops {
+		if strings.HasPrefix(p.s[p.pos:], op) {
+			p.pos += len(op)
+			right, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			l, r := left, right
+			o := op
+			return boolFunc(func(n *Node, pos, size int) bool {
+				return compareValues(l.eval(n, pos, size), r.eval(n, pos, size), o)
+			}), nil
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	switch {
+	case p.s[p.pos] == '(':
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, fmt.Errorf("expected ')' at %d", p.pos)
+		}
+		p.pos++
+		return inner, nil
+	case p.s[p.pos] == '"' || p.s[p.pos] == '\'':
+		q := p.s[p.pos]
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.s) && p.s[p.pos] != q {
+			p.pos++
+		}
+		val := p.s[start:p.pos]
+		p.pos++
+		return litStr(val), nil
+	case p.s[p.pos] >= '0' && p.s[p.pos] <= '9':
+		start := p.pos
+		for p.pos < len(p.s) && (p.s[p.pos] >= '0' && p.s[p.pos] <= '9' || p.s[p.pos] == '.') {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, err
+		}
+		return litNum(f), nil
+	case p.consumeWord("last"):
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] == '(' {
+			p.pos += 2 // "()"
+		}
+		return fnFunc(func(_ *Node, _, size int) any { return float64(size) }), nil
+	case p.consumeWord("position"):
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] == '(' {
+			p.pos += 2
+		}
+		return fnFunc(func(_ *Node, pos, _ int) any { return float64(pos) }), nil
+	default:
+		return p.parseFuncOrPath()
+	}
+}
+
+func (p *exprParser) parseFuncOrPath() (exprNode, error) {
+	name := p.parseIdent()
+	if name == "" {
+		return nil, fmt.Errorf("unexpected %q at %d", p.s[p.pos:], p.pos)
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		var args []exprNode
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] != ')' {
+			for {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				p.skipSpace()
+				if p.pos < len(p.s) && p.s[p.pos] == ',' {
+					p.pos++
+					continue
+				}
+				break
+			}
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, fmt.Errorf("expected ')' at %d", p.pos)
+		}
+		p.pos++
+		return compileXPathFunc(name, args)
+	}
+	// "@attr" or a relative node-test like "self::node" are handled by the
+	// caller's context node; within a predicate we only support @attr and text().
+	return pathRef(name), nil
+}
+
+func (p *exprParser) parseIdent() string {
+	p.skipSpace()
+	start := p.pos
+	if p.pos < len(p.s) && p.s[p.pos] == '@' {
+		p.pos++
+	}
+	for p.pos < len(p.s) && isXPathNameChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func compileXPathFunc(name string, args []exprNode) (exprNode, error) {
+	switch name {
+	case "contains":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("contains() takes 2 arguments")
+		}
+		a, b := args[0], args[1]
+		return fnFunc(func(n *Node, pos, size int) any {
+			return strings.Contains(toStr(a.eval(n, pos, size)), toStr(b.eval(n, pos, size)))
+		}), nil
+	case "starts-with":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("starts-with() takes 2 arguments")
+		}
+		a, b := args[0], args[1]
+		return fnFunc(func(n *Node, pos, size int) any {
+			return strings.HasPrefix(toStr(a.eval(n, pos, size)), toStr(b.eval(n, pos, size)))
+		}), nil
+	case "normalize-space":
+		var a exprNode = pathRef("")
+		if len(args) == 1 {
+			a = args[0]
+		}
+		return fnFunc(func(n *Node, pos, size int) any {
+			return strings.Join(strings.Fields(toStr(a.eval(n, pos, size))), " ")
+		}), nil
+	case "string-length":
+		var a exprNode = pathRef("")
+		if len(args) == 1 {
+			a = args[0]
+		}
+		return fnFunc(func(n *Node, pos, size int) any {
+			return float64(len(toStr(a.eval(n, pos, size))))
+		}), nil
+	case "count":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("count() takes 1 argument")
+		}
+		ref, ok := args[0].(pathRef)
+		if !ok {
+			return nil, fmt.Errorf("count() expects a node-set argument")
+		}
+		return fnFunc(func(n *Node, _, _ int) any {
+			return float64(countChildren(n, string(ref)))
+		}), nil
+	case "local-name":
+		var a exprNode = pathRef("")
+		if len(args) == 1 {
+			a = args[0]
+		}
+		return fnFunc(func(n *Node, pos, size int) any {
+			ref, ok := a.(pathRef)
+			if ok && ref == "" {
+				return localName(n.Data)
+			}
+			return toStr(a.eval(n, pos, size))
+		}), nil
+	case "text":
+		return fnFunc(func(n *Node, _, _ int) any { return textContent(n) }), nil
+	default:
+		return nil, fmt.Errorf("unsupported function %q", name)
+	}
+}
+
+func countChildren(n *Node, ref string) int {
+	name := strings.TrimPrefix(ref, "child::")
+	count := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if name == "" || name == "*" || c.Data == name {
+			count++
+		}
+	}
+	return count
+}
+
+func localName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// pathRef resolves to a node's attribute value ("@name"); "" and any other
+// name resolve to the node's own text content, not to a child element's.
+type pathRef string
+
+func (r pathRef) eval(n *Node, _, _ int) any {
+	ref := string(r)
+	switch {
+	case ref == "":
+		return textContent(n)
+	case strings.HasPrefix(ref, "@"):
+		v, _ := attrVal(n, ref[1:])
+		return v
+	default:
+		return textContent(n)
+	}
+}
+
+type litStr string
+
+func (s litStr) eval(*Node, int, int) any { return string(s) }
+
+type litNum float64
+
+func (f litNum) eval(*Node, int, int) any { return float64(f) }
+
+type boolFunc func(n *Node, pos, size int) bool
+
+func (f boolFunc) eval(n *Node, pos, size int) any { return f(n, pos, size) }
+
+type fnFunc func(n *Node, pos, size int) any
+
+func (f fnFunc) eval(n *Node, pos, size int) any { return f(n, pos, size) }
+
+func truthy(v any) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case float64:
+		return x != 0
+	case string:
+		return x != ""
+	default:
+		return false
+	}
+}
+
+func toStr(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case bool:
+		if x {
+			return "true"
+		}
+		return "false"
+	default:
+		return ""
+	}
+}
+
+func toNum(v any) float64 {
+	switch x := v.(type) {
+	case float64:
+		return x
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f
+	case bool:
+		if x {
+			return 1
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+func compareValues(a, b any, op string) bool {
+	_, aIsStr := a.(string)
+	_, bIsStr := b.(string)
+	if aIsStr || bIsStr {
+		as, bs := toStr(a), toStr(b)
+		switch op {
+		case "=":
+			return as == bs
+		case "!=":
+			return as != bs
+		}
+	}
+	af, bf := toNum(a), toNum(b)
+	switch op {
+	case "=":
+		return af == bf
+	case "!=":
+		return af != bf
+	case "<":
+		return af < bf
+	case "<=":
+		return af <= bf
+	case ">":
+		return af > bf
+	case ">=":
+		return af >= bf
+	}
+	return false
+}
+
+func textContent(n *Node) string {
+	var b strings.Builder
+	var walk func(*Node)
+	walk = func(n *Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// -----------------------------------------------------------------------------
+
+// evalXPath evaluates the compiled expression starting from each node in
+// start, streaming results through yield in document order, without
+// duplicates. Some axes (ancestor, preceding-sibling, ...) walk the tree
+// backwards, so the result is explicitly re-sorted into document order
+// rather than just relying on the order evalStep happened to produce it in.
+func evalXPath(e *xpathExpr, start []*Node, yield func(*Node) bool) {
+	cur := start
+	for _, step := range e.steps {
+		cur = evalStep(step, cur)
+	}
+	seen := make(map[*Node]bool, len(cur))
+	uniq := make([]*Node, 0, len(cur))
+	for _, n := range cur {
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		uniq = append(uniq, n)
+	}
+	sort.Slice(uniq, func(i, j int) bool { return documentOrderLess(uniq[i], uniq[j]) })
+	for _, n := range uniq {
+		if !yield(n) {
+			return
+		}
+	}
+}
+
+// documentOrderLess reports whether a precedes b in document order. It walks
+// both nodes' ancestor chains to find where they diverge, then compares
+// sibling order at that point; an ancestor is considered to precede its own
+// descendants.
+func documentOrderLess(a, b *Node) bool {
+	if a == b {
+		return false
+	}
+	aPath, bPath := ancestorPath(a), ancestorPath(b)
+	i := 0
+	for i < len(aPath) && i < len(bPath) && aPath[i] == bPath[i] {
+		i++
+	}
+	if i == len(aPath) {
+		return true
+	}
+	if i == len(bPath) {
+		return false
+	}
+	for s := aPath[i].NextSibling; s != nil; s = s.NextSibling {
+		if s == bPath[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// ancestorPath returns the chain of nodes from n's root down to n itself.
+func ancestorPath(n *Node) []*Node {
+	var path []*Node
+	for p := n; p != nil; p = p.Parent {
+		path = append(path, p)
+	}
+	for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+		path[l], path[r] = path[r], path[l]
+	}
+	return path
+}
+
+func evalStep(step xpathStep, ctx []*Node) []*Node {
+	var out []*Node
+	for _, n := range ctx {
+		cands := axisNodes(n, step.axis)
+		matched := make([]*Node, 0, len(cands))
+		for _, c := range cands {
+			if nodeTestMatches(c, step) {
+				matched = append(matched, c)
+			}
+		}
+		size := len(matched)
+		for i, c := range matched {
+			ok := true
+			for _, pred := range step.predicates {
+				if !pred(c, i+1, size) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+func nodeTestMatches(n *Node, step xpathStep) bool {
+	if step.textTest {
+		return n.Type == html.TextNode
+	}
+	if n.Type != html.ElementNode {
+		return false
+	}
+	return step.name == "*" || step.name == "" || n.Data == step.name
+}
+
+func axisNodes(n *Node, axis xpathAxis) []*Node {
+	var out []*Node
+	switch axis {
+	case axisChild:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			out = append(out, c)
+		}
+	case axisDescendant:
+		collectDescendants(n, &out, false)
+	case axisDescendantOrSelf:
+		collectDescendants(n, &out, true)
+	case axisParent:
+		if n.Parent != nil {
+			out = append(out, n.Parent)
+		}
+	case axisAncestor:
+		for p := n.Parent; p != nil; p = p.Parent {
+			out = append(out, p)
+		}
+	case axisSelf:
+		out = append(out, n)
+	case axisFollowingSibling:
+		for s := n.NextSibling; s != nil; s = s.NextSibling {
+			out = append(out, s)
+		}
+	case axisPrecedingSibling:
+		for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func collectDescendants(n *Node, out *[]*Node, self bool) {
+	if self {
+		*out = append(*out, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		*out = append(*out, c)
+		collectDescendants(c, out, false)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// XGo_XPath evaluates the given XPath 1.0 expression against each node in p
+// and returns a NodeSet of the matching result nodes, in document order
+// without duplicates. The expression is compiled once and cached by its
+// source text.
+func (p NodeSet) XGo_XPath(expr string) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	compiled, err := compileXPathCached(expr)
+	if err != nil {
+		return NodeSet{Err: err}
+	}
+	return NodeSet{
+		Data: func(yield func(*Node) bool) {
+			var start []*Node
+			p.data()(func(n *Node) bool {
+				start = append(start, n)
+				return true
+			})
+			evalXPath(compiled, start, yield)
+		},
+	}
+}
+
+// XGo_XPathValue evaluates expr against each node in p and returns a
+// ValueSet of the text content of the matching nodes, suitable for
+// expressions whose result is naturally a string (e.g. ending in text()).
+func (p NodeSet) XGo_XPathValue(expr string) ValueSet {
+	if p.Err != nil {
+		return ValueSet{Err: p.Err}
+	}
+	compiled, err := compileXPathCached(expr)
+	if err != nil {
+		return ValueSet{Err: err}
+	}
+	return ValueSet{
+		Data: func(yield func(Value) bool) {
+			var start []*Node
+			p.data()(func(n *Node) bool {
+				start = append(start, n)
+				return true
+			})
+			ok := true
+			evalXPath(compiled, start, func(n *Node) bool {
+				ok = yield(Value{X_0: textContent(n)})
+				return ok
+			})
+		},
+	}
+}
+
+// -----------------------------------------------------------------------------
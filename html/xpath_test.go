@@ -0,0 +1,110 @@
+package html
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+const xpathTestHTML = `<html><body>
+<div id="main" data-t="main">
+  <p data-t="p1" class="item">one</p>
+  <p data-t="p2" class="item wide">two</p>
+  <p data-t="p3" class="item">three</p>
+  <ul>
+    <li data-t="li1">a</li>
+    <li data-t="li2">b</li>
+  </ul>
+</div>
+</body></html>`
+
+func xpathTags(t *testing.T, ns NodeSet) []string {
+	t.Helper()
+	var got []string
+	ns.XGo_Enum()(func(n *Node) bool {
+		if v, ok := attrVal(n, "data-t"); ok {
+			got = append(got, v)
+		}
+		return true
+	})
+	sort.Strings(got)
+	return got
+}
+
+func TestXPathMatch(t *testing.T) {
+	doc := New(strings.NewReader(xpathTestHTML))
+	root := doc
+
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{"descendant-all", "//p", []string{"p1", "p2", "p3"}},
+		{"positional-first", "//p[1]", []string{"p1"}},
+		{"positional-second", "//p[2]", []string{"p2"}},
+		{"position-function", "//p[position()=3]", []string{"p3"}},
+		{"last-function", "//li[last()]", []string{"li2"}},
+		{"attr-predicate", `//p[@class="item"]`, []string{"p1", "p3"}},
+		{"contains-function", `//p[contains(@class,"wide")]`, []string{"p2"}},
+		{"child-axis", "/html/body/div/p", []string{"p1", "p2", "p3"}},
+		{"parent-axis", "//li/parent::ul/..", []string{"main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := root.XGo_XPath(tt.expr)
+			if ns.Err != nil {
+				t.Fatalf("XGo_XPath(%q) error: %v", tt.expr, ns.Err)
+			}
+			got := xpathTags(t, ns)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("XGo_XPath(%q) = %v, want %v", tt.expr, got, want)
+			}
+		})
+	}
+}
+
+func TestXPathResultsInDocumentOrder(t *testing.T) {
+	doc := New(strings.NewReader(xpathTestHTML))
+	// preceding-sibling:: walks backwards from p3, so without re-sorting this
+	// would come back as [p2, p1] - the reverse of document order.
+	ns := doc.XGo_XPath("//p[3]/preceding-sibling::p")
+	if ns.Err != nil {
+		t.Fatalf("XGo_XPath error: %v", ns.Err)
+	}
+	var got []string
+	ns.XGo_Enum()(func(n *Node) bool {
+		if v, ok := attrVal(n, "data-t"); ok {
+			got = append(got, v)
+		}
+		return true
+	})
+	want := []string{"p1", "p2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v in document order", got, want)
+	}
+}
+
+func TestXPathCacheHitReturnsSameCompiledExpr(t *testing.T) {
+	e1, err := compileXPathCached("//p[1]")
+	if err != nil {
+		t.Fatalf("compileXPathCached error: %v", err)
+	}
+	e2, err := compileXPathCached("//p[1]")
+	if err != nil {
+		t.Fatalf("compileXPathCached error: %v", err)
+	}
+	if e1 != e2 {
+		t.Fatal("expected a cache hit to return the same compiled expression")
+	}
+}
+
+func TestXPathInvalid(t *testing.T) {
+	doc := New(strings.NewReader(xpathTestHTML))
+	ns := doc.XGo_XPath("//p[")
+	if ns.Err == nil {
+		t.Fatal("expected an error for an unterminated predicate")
+	}
+}
@@ -0,0 +1,305 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+//
+// compileFilter parses the condition inside a JSONPath filter expression,
+// e.g. `@.price<10 && @.tag=="x"`, into a filterExpr that can be evaluated
+// against each candidate value (bound to `@`).
+
+type filterExpr interface {
+	eval(self any) bool
+}
+
+func compileFilter(src string) (filterExpr, error) {
+	p := &filterParser{s: strings.TrimSpace(src)}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("dql/json: invalid filter %q: %w", src, err)
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("dql/json: unexpected %q in filter %q", p.s[p.pos:], src)
+	}
+	return e, nil
+}
+
+type filterParser struct {
+	s   string
+	pos int
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *filterParser) consume(tok string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.s[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.consume("!") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner}, nil
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, fmt.Errorf("expected ')' at %d", p.pos)
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.s[p.pos:], op) {
+			p.pos += len(op)
+			right, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			return cmpExpr{left, right, op}, nil
+		}
+	}
+	// bare value (e.g. `@.active`): truthy test.
+	return truthyExpr{left}, nil
+}
+
+// filterValue resolves to a scalar from either a literal or a `@`-rooted path.
+type filterValue interface {
+	resolve(self any) any
+}
+
+func (p *filterParser) parseValue() (filterValue, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, fmt.Errorf("unexpected end of filter expression")
+	}
+	switch {
+	case p.s[p.pos] == '@':
+		p.pos++
+		var path []string
+		for p.pos < len(p.s) && p.s[p.pos] == '.' {
+			p.pos++
+			start := p.pos
+			for p.pos < len(p.s) && isFieldChar(p.s[p.pos]) {
+				p.pos++
+			}
+			path = append(path, p.s[start:p.pos])
+		}
+		return selfPath{path: path}, nil
+	case p.s[p.pos] == '\'' || p.s[p.pos] == '"':
+		q := p.s[p.pos]
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.s) && p.s[p.pos] != q {
+			p.pos++
+		}
+		val := p.s[start:p.pos]
+		p.pos++
+		return litValue{val}, nil
+	case strings.HasPrefix(p.s[p.pos:], "true"):
+		p.pos += 4
+		return litValue{true}, nil
+	case strings.HasPrefix(p.s[p.pos:], "false"):
+		p.pos += 5
+		return litValue{false}, nil
+	case strings.HasPrefix(p.s[p.pos:], "null"):
+		p.pos += 4
+		return litValue{nil}, nil
+	default:
+		start := p.pos
+		if p.s[p.pos] == '-' {
+			p.pos++
+		}
+		for p.pos < len(p.s) && (p.s[p.pos] >= '0' && p.s[p.pos] <= '9' || p.s[p.pos] == '.') {
+			p.pos++
+		}
+		if p.pos == start {
+			return nil, fmt.Errorf("unexpected %q at %d", p.s[p.pos:], p.pos)
+		}
+		f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, err
+		}
+		return litValue{f}, nil
+	}
+}
+
+func isFieldChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// -----------------------------------------------------------------------------
+
+type selfPath struct{ path []string }
+
+func (s selfPath) resolve(self any) any {
+	v := self
+	for _, name := range s.path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[name]
+	}
+	return v
+}
+
+type litValue struct{ v any }
+
+func (l litValue) resolve(any) any { return l.v }
+
+type cmpExpr struct {
+	left, right filterValue
+	op          string
+}
+
+func (c cmpExpr) eval(self any) bool {
+	return compareAny(c.left.resolve(self), c.right.resolve(self), c.op)
+}
+
+type truthyExpr struct{ v filterValue }
+
+func (t truthyExpr) eval(self any) bool {
+	v := t.v.resolve(self)
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	default:
+		return true
+	}
+}
+
+type andExpr struct{ left, right filterExpr }
+
+func (e andExpr) eval(self any) bool { return e.left.eval(self) && e.right.eval(self) }
+
+type orExpr struct{ left, right filterExpr }
+
+func (e orExpr) eval(self any) bool { return e.left.eval(self) || e.right.eval(self) }
+
+type notExpr struct{ inner filterExpr }
+
+func (e notExpr) eval(self any) bool { return !e.inner.eval(self) }
+
+func compareAny(a, b any, op string) bool {
+	if as, aok := a.(string); aok {
+		bs, bok := b.(string)
+		if bok {
+			switch op {
+			case "==":
+				return as == bs
+			case "!=":
+				return as != bs
+			case "<":
+				return as < bs
+			case "<=":
+				return as <= bs
+			case ">":
+				return as > bs
+			case ">=":
+				return as >= bs
+			}
+		}
+	}
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		switch op {
+		case "==":
+			return af == bf
+		case "!=":
+			return af != bf
+		case "<":
+			return af < bf
+		case "<=":
+			return af <= bf
+		case ">":
+			return af > bf
+		case ">=":
+			return af >= bf
+		}
+	}
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	}
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	default:
+		return 0, false
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,281 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+
+	"github.com/goplus/dql/stream"
+	"github.com/goplus/dql/util"
+)
+
+var (
+	ErrNotFound      = errors.New("entity not found")
+	ErrMultiEntities = errors.New("too many entities found")
+)
+
+// nopIter is a no-operation iterator that yields no values.
+func nopIter[T any](yield func(T) bool) {}
+
+func singleton(v any) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		yield(v)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// NodeSet represents a set of decoded JSON values (objects, arrays, or scalars).
+type NodeSet struct {
+	Data iter.Seq[any]
+	Err  error
+}
+
+// New decodes a single JSON document from r and returns a NodeSet containing it.
+// If there is an error during decoding, the NodeSet's Err field is set.
+func New(r io.Reader) NodeSet {
+	var v any
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return NodeSet{Err: err}
+	}
+	return NodeSet{Data: singleton(v)}
+}
+
+// Source creates a NodeSet from various types of sources:
+// - string: treated as an URL to read JSON content from.
+// - []byte: treated as raw JSON content.
+// - io.Reader: reads JSON content from the reader.
+// - iter.Seq[any]: directly uses the provided sequence of values.
+// - NodeSet: returns the provided NodeSet as is.
+// - any other value: treated as an already-decoded JSON value.
+func Source(r any) (ret NodeSet) {
+	switch v := r.(type) {
+	case string:
+		f, err := stream.Open(v)
+		if err != nil {
+			return NodeSet{Err: err}
+		}
+		defer f.Close()
+		return New(f)
+	case []byte:
+		return New(bytes.NewReader(v))
+	case io.Reader:
+		return New(v)
+	case iter.Seq[any]:
+		return NodeSet{Data: v}
+	case NodeSet:
+		return v
+	default:
+		return NodeSet{Data: singleton(v)}
+	}
+}
+
+// XGo_Enum returns an iterator over the values in the NodeSet.
+func (p NodeSet) XGo_Enum() iter.Seq[any] {
+	if p.Err != nil {
+		return nopIter[any]
+	}
+	return p.Data
+}
+
+// XGo_Field returns a NodeSet containing the named field of each object in
+// the NodeSet. Values that are not objects, or that lack the field, are skipped.
+func (p NodeSet) XGo_Field(name string) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	return NodeSet{
+		Data: func(yield func(any) bool) {
+			ok := true
+			p.Data(func(v any) bool {
+				if m, isMap := v.(map[string]any); isMap {
+					if fv, found := m[name]; found {
+						ok = yield(fv)
+					}
+				}
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_Index returns a NodeSet containing the i-th element of each array in
+// the NodeSet. A negative index counts from the end. Out-of-range indices,
+// and values that are not arrays, are skipped.
+func (p NodeSet) XGo_Index(i int) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	return NodeSet{
+		Data: func(yield func(any) bool) {
+			ok := true
+			p.Data(func(v any) bool {
+				if a, isArr := v.([]any); isArr {
+					idx := i
+					if idx < 0 {
+						idx += len(a)
+					}
+					if idx >= 0 && idx < len(a) {
+						ok = yield(a[idx])
+					}
+				}
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_Any returns a NodeSet containing every value reachable from the
+// NodeSet by recursive descent: each value itself, plus (recursively) every
+// object field value and array element.
+func (p NodeSet) XGo_Any() NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	return NodeSet{
+		Data: func(yield func(any) bool) {
+			ok := true
+			var walk func(v any) bool
+			walk = func(v any) bool {
+				if ok = yield(v); !ok {
+					return false
+				}
+				switch x := v.(type) {
+				case map[string]any:
+					for _, fv := range x {
+						if !walk(fv) {
+							return false
+						}
+					}
+				case []any:
+					for _, ev := range x {
+						if !walk(ev) {
+							return false
+						}
+					}
+				}
+				return true
+			}
+			p.Data(func(v any) bool {
+				ok = walk(v)
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_Where returns a NodeSet containing the values in the NodeSet for which pred returns true.
+func (p NodeSet) XGo_Where(pred func(any) bool) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	return NodeSet{
+		Data: func(yield func(any) bool) {
+			p.Data(func(v any) bool {
+				if pred(v) {
+					return yield(v)
+				}
+				return true
+			})
+		},
+	}
+}
+
+// XGo_Path evaluates the given JSONPath expression against each value in the
+// NodeSet and returns a NodeSet of the matching results. The expression is
+// compiled once and then evaluated lazily.
+func (p NodeSet) XGo_Path(expr string) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	compiled, err := compileJSONPath(expr)
+	if err != nil {
+		return NodeSet{Err: err}
+	}
+	return NodeSet{
+		Data: func(yield func(any) bool) {
+			ok := true
+			p.Data(func(v any) bool {
+				compiled.eval(v, func(r any) bool {
+					ok = yield(r)
+					return ok
+				})
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_0 returns the first value in the NodeSet, or ErrNotFound if the set is empty.
+func (p NodeSet) XGo_0() (val any, err error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	err = ErrNotFound
+	p.Data(func(v any) bool {
+		val, err = v, nil
+		return false
+	})
+	return
+}
+
+// XGo_1 returns the first value in the NodeSet, or ErrNotFound if the set is empty.
+// If there is more than one value in the set, ErrMultiEntities is returned.
+func (p NodeSet) XGo_1() (val any, err error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	first := true
+	err = ErrNotFound
+	p.Data(func(v any) bool {
+		if first {
+			val, err = v, nil
+			first = false
+			return true
+		}
+		err = ErrMultiEntities
+		return false
+	})
+	return
+}
+
+// -----------------------------------------------------------------------------
+
+// XGo_String returns a ValueSet with one entry per value in the NodeSet: the
+// value itself if it is a string, otherwise an entry whose X_1 is ErrNotFound.
+func (p NodeSet) XGo_String() util.ValueSet[string] {
+	return scalarValueSet[string](p)
+}
+
+// XGo_Number returns a ValueSet with one entry per value in the NodeSet: the
+// value itself if it is a float64, otherwise an entry whose X_1 is ErrNotFound.
+func (p NodeSet) XGo_Number() util.ValueSet[float64] {
+	return scalarValueSet[float64](p)
+}
+
+// XGo_Bool returns a ValueSet with one entry per value in the NodeSet: the
+// value itself if it is a bool, otherwise an entry whose X_1 is ErrNotFound.
+func (p NodeSet) XGo_Bool() util.ValueSet[bool] {
+	return scalarValueSet[bool](p)
+}
+
+func scalarValueSet[T any](p NodeSet) util.ValueSet[T] {
+	if p.Err != nil {
+		return util.ValueSet[T]{Err: p.Err}
+	}
+	return util.ValueSet[T]{
+		Data: func(yield func(util.Value[T]) bool) {
+			p.Data(func(v any) bool {
+				t, ok := v.(T)
+				if !ok {
+					return yield(util.Value[T]{X_1: ErrNotFound})
+				}
+				return yield(util.Value[T]{X_0: t})
+			})
+		},
+	}
+}
+
+// -----------------------------------------------------------------------------
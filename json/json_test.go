@@ -0,0 +1,79 @@
+package json
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+const jsonTestDoc = `{
+  "name": "root",
+  "tags": ["a", "b", "c"],
+  "child": {"name": "nested", "tags": ["d"]}
+}`
+
+func TestFieldAndIndex(t *testing.T) {
+	doc := New(strings.NewReader(jsonTestDoc))
+	if doc.Err != nil {
+		t.Fatalf("New error: %v", doc.Err)
+	}
+
+	name, err := doc.XGo_Field("name").XGo_0()
+	if err != nil || name != "root" {
+		t.Fatalf("XGo_Field(\"name\").XGo_0() = (%v, %v), want (\"root\", nil)", name, err)
+	}
+
+	first, err := doc.XGo_Field("tags").XGo_Index(0).XGo_0()
+	if err != nil || first != "a" {
+		t.Fatalf("XGo_Index(0).XGo_0() = (%v, %v), want (\"a\", nil)", first, err)
+	}
+
+	last, err := doc.XGo_Field("tags").XGo_Index(-1).XGo_0()
+	if err != nil || last != "c" {
+		t.Fatalf("XGo_Index(-1).XGo_0() = (%v, %v), want (\"c\", nil)", last, err)
+	}
+}
+
+func TestFieldMissingIsSkipped(t *testing.T) {
+	doc := New(strings.NewReader(jsonTestDoc))
+	_, err := doc.XGo_Field("missing").XGo_0()
+	if err != ErrNotFound {
+		t.Errorf("XGo_Field(\"missing\").XGo_0() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAny(t *testing.T) {
+	doc := New(strings.NewReader(jsonTestDoc))
+	var names []string
+	doc.XGo_Any().XGo_Enum()(func(v any) bool {
+		if m, ok := v.(map[string]any); ok {
+			if n, ok := m["name"].(string); ok {
+				names = append(names, n)
+			}
+		}
+		return true
+	})
+	sort.Strings(names)
+	if want := []string{"nested", "root"}; strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("XGo_Any() names = %v, want %v", names, want)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	doc := New(strings.NewReader(jsonTestDoc))
+	count := 0
+	doc.XGo_Field("tags").XGo_Path("[*]").XGo_Where(func(v any) bool {
+		s, ok := v.(string)
+		return ok && s != "b"
+	}).XGo_Enum()(func(any) bool { count++; return true })
+	if count != 2 {
+		t.Errorf("XGo_Where() yielded %d values, want 2", count)
+	}
+}
+
+func TestInvalidJSON(t *testing.T) {
+	doc := New(strings.NewReader("{not json"))
+	if doc.Err == nil {
+		t.Fatal("expected an error for malformed JSON")
+	}
+}
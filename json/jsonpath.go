@@ -0,0 +1,479 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+//
+// A small JSONPath engine supporting the subset used in practice: `$`,
+// `.name`, `['name']`, `[n]`, slices, wildcards, recursive descent (`..name`),
+// filter expressions (`[?(@.price<10 && @.tag=="x")]`), and unions
+// (`['a','b']`, `[0,2]`).
+
+// jsonPath is a compiled JSONPath expression: a sequence of segments applied
+// left to right against the node set produced by the previous segment.
+type jsonPath struct {
+	segments []pathSegment
+}
+
+type pathSegment interface {
+	apply(v any, yield func(any) bool) bool
+}
+
+func compileJSONPath(expr string) (*jsonPath, error) {
+	p := &jsonPathParser{s: strings.TrimSpace(expr)}
+	if strings.HasPrefix(p.s, "$") {
+		p.pos = 1
+	}
+	path := &jsonPath{}
+	for p.pos < len(p.s) {
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, fmt.Errorf("dql/json: invalid path %q: %w", expr, err)
+		}
+		path.segments = append(path.segments, seg)
+	}
+	return path, nil
+}
+
+// eval streams every value reached by walking v through the compiled path.
+func (p *jsonPath) eval(v any, yield func(any) bool) bool {
+	cur := []any{v}
+	for _, seg := range p.segments {
+		var next []any
+		ok := true
+		for _, c := range cur {
+			if !seg.apply(c, func(r any) bool {
+				next = append(next, r)
+				return true
+			}) {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+		cur = next
+	}
+	for _, v := range cur {
+		if !yield(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// -----------------------------------------------------------------------------
+
+type jsonPathParser struct {
+	s   string
+	pos int
+}
+
+func (p *jsonPathParser) parseSegment() (pathSegment, error) {
+	switch {
+	case strings.HasPrefix(p.s[p.pos:], ".."):
+		p.pos += 2
+		name := p.parseDotName()
+		return recursiveSeg{name: name}, nil
+	case strings.HasPrefix(p.s[p.pos:], "."):
+		p.pos++
+		name := p.parseDotName()
+		if name == "*" {
+			return wildcardSeg{}, nil
+		}
+		return fieldSeg{name: name}, nil
+	case strings.HasPrefix(p.s[p.pos:], "["):
+		return p.parseBracket()
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", p.s[p.pos:], p.pos)
+	}
+}
+
+func (p *jsonPathParser) parseDotName() string {
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != '.' && p.s[p.pos] != '[' {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *jsonPathParser) parseBracket() (pathSegment, error) {
+	p.pos++ // '['
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '*' {
+		p.pos++
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ']' {
+			return nil, fmt.Errorf("expected ']' at %d", p.pos)
+		}
+		p.pos++
+		return wildcardSeg{}, nil
+	}
+	if p.pos < len(p.s) && p.s[p.pos] == '?' {
+		p.pos++
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != '(' {
+			return nil, fmt.Errorf("expected '(' at %d", p.pos)
+		}
+		raw, err := p.parseBalanced('(', ')')
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ']' {
+			return nil, fmt.Errorf("expected ']' at %d", p.pos)
+		}
+		p.pos++
+		cond, err := compileFilter(raw)
+		if err != nil {
+			return nil, err
+		}
+		return filterSeg{cond: cond}, nil
+	}
+	if p.pos < len(p.s) && (p.s[p.pos] == '\'' || p.s[p.pos] == '"') {
+		var names []string
+		for {
+			name, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+			p.skipSpace()
+			if p.pos < len(p.s) && p.s[p.pos] == ',' {
+				p.pos++
+				p.skipSpace()
+				continue
+			}
+			break
+		}
+		if p.pos >= len(p.s) || p.s[p.pos] != ']' {
+			return nil, fmt.Errorf("expected ']' at %d", p.pos)
+		}
+		p.pos++
+		if len(names) == 1 {
+			return fieldSeg{name: names[0]}, nil
+		}
+		return unionFieldSeg{names: names}, nil
+	}
+	// numeric index, union of indices, or a slice.
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != ']' {
+		p.pos++
+	}
+	if p.pos >= len(p.s) {
+		return nil, fmt.Errorf("unterminated '['")
+	}
+	raw := p.s[start:p.pos]
+	p.pos++
+	return parseIndexExpr(raw)
+}
+
+func (p *jsonPathParser) parseQuoted() (string, error) {
+	q := p.s[p.pos]
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != q {
+		p.pos++
+	}
+	if p.pos >= len(p.s) {
+		return "", fmt.Errorf("unterminated string")
+	}
+	val := p.s[start:p.pos]
+	p.pos++
+	return val, nil
+}
+
+func (p *jsonPathParser) parseBalanced(open, close byte) (string, error) {
+	p.pos++ // opening char
+	start := p.pos
+	depth := 1
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				raw := p.s[start:p.pos]
+				p.pos++
+				return raw, nil
+			}
+		}
+		p.pos++
+	}
+	return "", fmt.Errorf("unterminated %q", string(open))
+}
+
+func (p *jsonPathParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func parseIndexExpr(raw string) (pathSegment, error) {
+	if strings.Contains(raw, ",") {
+		var idxs []int
+		for _, part := range strings.Split(raw, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			idxs = append(idxs, n)
+		}
+		return unionIndexSeg{idxs: idxs}, nil
+	}
+	if strings.Contains(raw, ":") {
+		parts := strings.Split(raw, ":")
+		sl := sliceSeg{step: 1}
+		var err error
+		if strings.TrimSpace(parts[0]) != "" {
+			if sl.start, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+				return nil, err
+			}
+			sl.hasStart = true
+		}
+		if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+			if sl.end, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+				return nil, err
+			}
+			sl.hasEnd = true
+		}
+		if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+			if sl.step, err = strconv.Atoi(strings.TrimSpace(parts[2])); err != nil {
+				return nil, err
+			}
+		}
+		return sl, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+	return indexSeg{idx: n}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type fieldSeg struct{ name string }
+
+func (s fieldSeg) apply(v any, yield func(any) bool) bool {
+	if m, ok := v.(map[string]any); ok {
+		if fv, found := m[s.name]; found {
+			return yield(fv)
+		}
+	}
+	return true
+}
+
+type unionFieldSeg struct{ names []string }
+
+func (s unionFieldSeg) apply(v any, yield func(any) bool) bool {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return true
+	}
+	for _, name := range s.names {
+		if fv, found := m[name]; found {
+			if !yield(fv) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type wildcardSeg struct{}
+
+func (wildcardSeg) apply(v any, yield func(any) bool) bool {
+	switch x := v.(type) {
+	case map[string]any:
+		for _, fv := range x {
+			if !yield(fv) {
+				return false
+			}
+		}
+	case []any:
+		for _, ev := range x {
+			if !yield(ev) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type indexSeg struct{ idx int }
+
+func (s indexSeg) apply(v any, yield func(any) bool) bool {
+	a, ok := v.([]any)
+	if !ok {
+		return true
+	}
+	idx := s.idx
+	if idx < 0 {
+		idx += len(a)
+	}
+	if idx >= 0 && idx < len(a) {
+		return yield(a[idx])
+	}
+	return true
+}
+
+type unionIndexSeg struct{ idxs []int }
+
+func (s unionIndexSeg) apply(v any, yield func(any) bool) bool {
+	a, ok := v.([]any)
+	if !ok {
+		return true
+	}
+	for _, idx := range s.idxs {
+		if idx < 0 {
+			idx += len(a)
+		}
+		if idx >= 0 && idx < len(a) {
+			if !yield(a[idx]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type sliceSeg struct {
+	start, end     int
+	hasStart       bool
+	hasEnd         bool
+	step           int
+}
+
+func (s sliceSeg) apply(v any, yield func(any) bool) bool {
+	a, ok := v.([]any)
+	if !ok {
+		return true
+	}
+	step := s.step
+	if step == 0 {
+		step = 1
+	}
+	if step < 0 {
+		start, end := len(a)-1, -1
+		if s.hasStart {
+			start = s.start
+			if start < 0 {
+				start += len(a)
+			}
+		}
+		if s.hasEnd {
+			end = s.end
+			if end < 0 {
+				end += len(a)
+			}
+		}
+		if start > len(a)-1 {
+			start = len(a) - 1
+		}
+		if end < -1 {
+			end = -1
+		}
+		for i := start; i > end; i += step {
+			if i < 0 || i >= len(a) {
+				continue
+			}
+			if !yield(a[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	start, end := 0, len(a)
+	if s.hasStart {
+		start = s.start
+		if start < 0 {
+			start += len(a)
+		}
+	}
+	if s.hasEnd {
+		end = s.end
+		if end < 0 {
+			end += len(a)
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(a) {
+		end = len(a)
+	}
+	for i := start; i < end; i += step {
+		if !yield(a[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// recursiveSeg implements "..name": it descends into every nested value and
+// yields each object field matching name (or, for name == "", every node).
+type recursiveSeg struct{ name string }
+
+func (s recursiveSeg) apply(v any, yield func(any) bool) bool {
+	var walk func(v any) bool
+	walk = func(v any) bool {
+		switch x := v.(type) {
+		case map[string]any:
+			if s.name != "" && s.name != "*" {
+				if fv, found := x[s.name]; found {
+					if !yield(fv) {
+						return false
+					}
+				}
+			} else {
+				for _, fv := range x {
+					if !yield(fv) {
+						return false
+					}
+				}
+			}
+			for _, fv := range x {
+				if !walk(fv) {
+					return false
+				}
+			}
+		case []any:
+			for _, ev := range x {
+				if (s.name == "" || s.name == "*") && !yield(ev) {
+					return false
+				}
+				if !walk(ev) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return walk(v)
+}
+
+type filterSeg struct{ cond filterExpr }
+
+func (s filterSeg) apply(v any, yield func(any) bool) bool {
+	a, ok := v.([]any)
+	if !ok {
+		a = []any{v}
+	}
+	for _, ev := range a {
+		if s.cond.eval(ev) {
+			if !yield(ev) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// -----------------------------------------------------------------------------
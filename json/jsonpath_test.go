@@ -0,0 +1,79 @@
+package json
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+const jsonPathTestDoc = `{
+  "store": {
+    "books": [
+      {"title": "A", "price": 10, "tag": "x"},
+      {"title": "B", "price": 20, "tag": "y"},
+      {"title": "C", "price": 5, "tag": "x"},
+      {"title": "D", "price": 30, "tag": "z"}
+    ]
+  }
+}`
+
+// titles runs expr against doc and collects the "title" string of every
+// matching value - either the value itself (a book's title), or, if it's an
+// object, its "title" field.
+func titles(t *testing.T, expr string) []string {
+	t.Helper()
+	ns := New(strings.NewReader(jsonPathTestDoc)).XGo_Path(expr)
+	if ns.Err != nil {
+		t.Fatalf("XGo_Path(%q) error: %v", expr, ns.Err)
+	}
+	var got []string
+	ns.XGo_Enum()(func(v any) bool {
+		switch x := v.(type) {
+		case string:
+			got = append(got, x)
+		case map[string]any:
+			if s, ok := x["title"].(string); ok {
+				got = append(got, s)
+			}
+		}
+		return true
+	})
+	sort.Strings(got)
+	return got
+}
+
+func TestJSONPathMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{"index", "$.store.books[0].title", []string{"A"}},
+		{"wildcard", "$.store.books[*].title", []string{"A", "B", "C", "D"}},
+		{"slice", "$.store.books[1:3].title", []string{"B", "C"}},
+		{"slice-negative-step", "$.store.books[::-1].title", []string{"A", "B", "C", "D"}},
+		{"union-index", "$.store.books[0,2].title", []string{"A", "C"}},
+		{"recursive", "$..title", []string{"A", "B", "C", "D"}},
+		{"filter-lt", "$.store.books[?(@.price<10)].title", []string{"C"}},
+		{"filter-and", `$.store.books[?(@.tag=="x" && @.price<10)].title`, []string{"C"}},
+		{"filter-or", `$.store.books[?(@.tag=="y" || @.tag=="z")].title`, []string{"B", "D"}},
+		{"filter-ne", `$.store.books[?(@.tag!="x")].title`, []string{"B", "D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := titles(t, tt.expr)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("XGo_Path(%q) = %v, want %v", tt.expr, got, want)
+			}
+		})
+	}
+}
+
+func TestJSONPathInvalid(t *testing.T) {
+	ns := New(strings.NewReader(jsonPathTestDoc)).XGo_Path("$.store.books[?(")
+	if ns.Err == nil {
+		t.Fatal("expected an error for an unterminated filter expression")
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+
+// ParallelConfig holds the pending XGo_Parallel/XGo_Ordered/XGo_WithContext
+// configuration of a pipeline. Source packages (html, util, ...) embed this
+// in their Set types and call Parallelize to materialize it.
+type ParallelConfig struct {
+	N       int
+	Ordered bool
+	Ctx     context.Context
+}
+
+// ParallelExpand turns src into a bounded worker-pool pipeline: elements are
+// pulled from src by a single dispatcher goroutine and dispatched to cfg.N
+// worker goroutines, each of which calls work on its job - this is where the
+// actual per-element cost (an attribute lookup, a user map, ...) is paid, so
+// it runs concurrently rather than in the single goroutine that ranges over
+// the result. work may yield zero, one, or several results per input. If
+// cfg.Ordered is set, a job's results are re-sequenced to match src's
+// original order before being emitted; otherwise they are emitted as soon as
+// a worker produces them. If cfg.N <= 0, src is driven directly in the
+// calling goroutine with no pool overhead. If cfg.Ctx is nil,
+// context.Background() is used; the pool also unwinds promptly if the
+// downstream yield stops early.
+func ParallelExpand[T, R any](src iter.Seq[T], cfg ParallelConfig, work func(v T, yield func(R) bool) bool) iter.Seq[R] {
+	if cfg.N <= 0 {
+		return func(yield func(R) bool) {
+			src(func(v T) bool {
+				return work(v, yield)
+			})
+		}
+	}
+	return func(yield func(R) bool) {
+		parent := cfg.Ctx
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithCancel(parent)
+		defer cancel()
+
+		type job struct {
+			idx int
+			val T
+		}
+		type result struct {
+			idx  int
+			vals []R
+		}
+		jobs := make(chan job)
+		go func() {
+			defer close(jobs)
+			i := 0
+			src(func(v T) bool {
+				select {
+				case jobs <- job{i, v}:
+					i++
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+		}()
+
+		results := make(chan result, cfg.N)
+		var wg sync.WaitGroup
+		for w := 0; w < cfg.N; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range jobs {
+					var vals []R
+					work(j.val, func(r R) bool {
+						vals = append(vals, r)
+						return true
+					})
+					select {
+					case results <- result{j.idx, vals}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		emit := func(vals []R) bool {
+			for _, v := range vals {
+				if !yield(v) {
+					return false
+				}
+			}
+			return true
+		}
+
+		if !cfg.Ordered {
+			for r := range results {
+				if !emit(r.vals) {
+					return
+				}
+			}
+			return
+		}
+
+		pending := map[int][]R{}
+		next := 0
+		for r := range results {
+			pending[r.idx] = r.vals
+			for {
+				vals, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				if !emit(vals) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ParallelMap specializes ParallelExpand to a 1:1 transform, run inside the
+// worker pool: `doc.any.parallel(8).attr("href")` performs the attribute
+// lookups themselves concurrently, not just the relaying of already-pulled
+// nodes.
+func ParallelMap[T, R any](src iter.Seq[T], cfg ParallelConfig, fn func(T) R) iter.Seq[R] {
+	return ParallelExpand(src, cfg, func(v T, yield func(R) bool) bool {
+		return yield(fn(v))
+	})
+}
+
+// ParallelFilter specializes ParallelExpand to a predicate test run inside
+// the worker pool, re-emitting the elements that pass.
+func ParallelFilter[T any](src iter.Seq[T], cfg ParallelConfig, keep func(T) bool) iter.Seq[T] {
+	return ParallelExpand(src, cfg, func(v T, yield func(T) bool) bool {
+		if keep(v) {
+			return yield(v)
+		}
+		return true
+	})
+}
+
+// Parallelize relays src through the worker pool unchanged. It's for
+// operators with no per-element work of their own to distribute (XGo_Enum,
+// XGo_0, XGo_1); operators that do real per-element work (XGo_Attr,
+// XGo_Node, ...) should use ParallelMap/ParallelFilter/ParallelExpand
+// instead so that work actually happens inside the pool.
+func Parallelize[T any](src iter.Seq[T], cfg ParallelConfig) iter.Seq[T] {
+	return ParallelExpand(src, cfg, func(v T, yield func(T) bool) bool {
+		return yield(v)
+	})
+}
+
+// -----------------------------------------------------------------------------
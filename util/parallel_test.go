@@ -0,0 +1,133 @@
+package util
+
+import (
+	"iter"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func seqOf(vals ...int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for _, v := range vals {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func collect[T any](seq iter.Seq[T]) []T {
+	var got []T
+	seq(func(v T) bool {
+		got = append(got, v)
+		return true
+	})
+	return got
+}
+
+func TestParallelMapUnordered(t *testing.T) {
+	got := collect(ParallelMap(seqOf(1, 2, 3, 4), ParallelConfig{N: 4}, func(v int) int { return v * 2 }))
+	sort.Ints(got)
+	if want := []int{2, 4, 6, 8}; !equalInts(got, want) {
+		t.Errorf("ParallelMap = %v, want %v (any order)", got, want)
+	}
+}
+
+func TestParallelMapOrdered(t *testing.T) {
+	got := collect(ParallelMap(seqOf(1, 2, 3, 4), ParallelConfig{N: 4, Ordered: true}, func(v int) int { return v * 2 }))
+	if want := []int{2, 4, 6, 8}; !equalInts(got, want) {
+		t.Errorf("ParallelMap (ordered) = %v, want %v", got, want)
+	}
+}
+
+func TestParallelFilter(t *testing.T) {
+	got := collect(ParallelFilter(seqOf(1, 2, 3, 4, 5), ParallelConfig{N: 3}, func(v int) bool { return v%2 == 0 }))
+	sort.Ints(got)
+	if want := []int{2, 4}; !equalInts(got, want) {
+		t.Errorf("ParallelFilter = %v, want %v", got, want)
+	}
+}
+
+func TestParallelExpandRunsDirectlyWhenNIsZero(t *testing.T) {
+	got := collect(ParallelExpand(seqOf(1, 2, 3), ParallelConfig{}, func(v int, yield func(int) bool) bool {
+		return yield(v * 10)
+	}))
+	if want := []int{10, 20, 30}; !equalInts(got, want) {
+		t.Errorf("ParallelExpand (N=0) = %v, want %v", got, want)
+	}
+}
+
+// TestParallelExpandRunsWorkConcurrently guards against ParallelMap/Filter
+// regressing into a passthrough relay that only distributes already-computed
+// values across workers: it asserts that work itself overlaps across
+// goroutines, not just that results are eventually collected.
+func TestParallelExpandRunsWorkConcurrently(t *testing.T) {
+	const n = 4
+	var (
+		mu      sync.Mutex
+		active  int
+		maxSeen int
+		start   = make(chan struct{})
+	)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	go func() {
+		wg.Wait()
+		close(start)
+	}()
+
+	results := collect(ParallelMap(seqOf(1, 2, 3, 4), ParallelConfig{N: n}, func(v int) int {
+		mu.Lock()
+		active++
+		if active > maxSeen {
+			maxSeen = active
+		}
+		mu.Unlock()
+		wg.Done()
+		<-start // block until every worker has entered its work func
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return v
+	}))
+
+	if maxSeen < n {
+		t.Errorf("max concurrent work calls = %d, want %d (work must run inside the pool, not just relay pre-pulled values)", maxSeen, n)
+	}
+	sort.Ints(results)
+	if want := []int{1, 2, 3, 4}; !equalInts(results, want) {
+		t.Errorf("results = %v, want %v", results, want)
+	}
+}
+
+// TestParallelExpandStopsEarly guards against the pool hanging (dispatcher or
+// workers blocked forever on a channel send) once the downstream consumer
+// stops pulling results.
+func TestParallelExpandStopsEarly(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		ParallelMap(seqOf(1, 2, 3, 4, 5, 6, 7, 8), ParallelConfig{N: 2}, func(v int) int { return v })(func(int) bool {
+			return false
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ParallelMap did not return after the consumer stopped early")
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
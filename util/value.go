@@ -17,6 +17,7 @@
 package util
 
 import (
+	"context"
 	"errors"
 	"iter"
 )
@@ -46,6 +47,14 @@ type Value[T any] = struct {
 type ValueSet[T any] struct {
 	Data iter.Seq[Value[T]]
 	Err  error
+
+	parallel ParallelConfig
+}
+
+// data returns the Values sequence with any pending XGo_Parallel/XGo_Ordered/
+// XGo_WithContext configuration materialized.
+func (p ValueSet[T]) data() iter.Seq[Value[T]] {
+	return Parallelize(p.Data, p.parallel)
 }
 
 // XGo_Enum returns an iterator over the Values in the ValueSet.
@@ -53,7 +62,39 @@ func (p ValueSet[T]) XGo_Enum() iter.Seq[Value[T]] {
 	if p.Err != nil {
 		return NopIter[Value[T]]
 	}
-	return p.Data
+	return p.data()
+}
+
+// XGo_Parallel returns a ValueSet that pulls from p through a worker pool of
+// n goroutines. XGo_Enum, XGo_0 and XGo_1 relay the Values through that pool
+// unchanged; no per-Value work of ValueSet's own runs inside it. Chain
+// XGo_Ordered to preserve p's original order, and
+// XGo_WithContext to bind a context whose cancellation shuts the pool down.
+func (p ValueSet[T]) XGo_Parallel(n int) ValueSet[T] {
+	if p.Err != nil {
+		return p
+	}
+	p.parallel.N = n
+	return p
+}
+
+// XGo_Ordered requests that a chained XGo_Parallel preserve p's original order.
+func (p ValueSet[T]) XGo_Ordered() ValueSet[T] {
+	if p.Err != nil {
+		return p
+	}
+	p.parallel.Ordered = true
+	return p
+}
+
+// XGo_WithContext binds ctx to a chained XGo_Parallel, so that cancelling ctx
+// stops the dispatcher and worker goroutines.
+func (p ValueSet[T]) XGo_WithContext(ctx context.Context) ValueSet[T] {
+	if p.Err != nil {
+		return p
+	}
+	p.parallel.Ctx = ctx
+	return p
 }
 
 // XGo_0 returns the first value in the ValueSet, or ErrNotFound if the set is empty.
@@ -63,7 +104,7 @@ func (p ValueSet[T]) XGo_0() (val T, err error) {
 		return
 	}
 	err = ErrNotFound
-	p.Data(func(v Value[T]) bool {
+	p.data()(func(v Value[T]) bool {
 		val, err = v.X_0, v.X_1
 		return false
 	})
@@ -79,7 +120,7 @@ func (p ValueSet[T]) XGo_1() (val T, err error) {
 	}
 	first := true
 	err = ErrNotFound
-	p.Data(func(v Value[T]) bool {
+	p.data()(func(v Value[T]) bool {
 		if first {
 			val, err = v.X_0, v.X_1
 			first = false
@@ -0,0 +1,353 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/goplus/dql/stream"
+	"github.com/goplus/dql/util"
+)
+
+var (
+	ErrNotFound      = errors.New("entity not found")
+	ErrMultiEntities = errors.New("too many entities found")
+)
+
+// nopIter is a no-operation iterator that yields no values.
+func nopIter[T any](yield func(T) bool) {}
+
+// -----------------------------------------------------------------------------
+
+// Attr represents an XML attribute.
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Node represents an XML element, built incrementally from the decoder's
+// token stream rather than via reflection-based unmarshalling.
+type Node struct {
+	Local       string
+	Space       string
+	Attr        []Attr
+	Parent      *Node
+	FirstChild  *Node
+	NextSibling *Node
+	text        string
+}
+
+// ChildNodes returns an iterator over the direct children of n.
+func (n *Node) ChildNodes() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// Descendants returns an iterator over all descendants of n, not including n itself.
+func (n *Node) Descendants() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		var walk func(*Node) bool
+		walk = func(n *Node) bool {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if !yield(c) {
+					return false
+				}
+				if !walk(c) {
+					return false
+				}
+			}
+			return true
+		}
+		walk(n)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// NodeSet represents a set of XML nodes. ns holds the prefix-to-namespace-URI
+// bindings registered via XGo_Namespace, used to resolve "ns:local" node names.
+type NodeSet struct {
+	Data iter.Seq[*Node]
+	Err  error
+	ns   map[string]string
+}
+
+// New parses the XML document from the provided reader and returns a NodeSet
+// containing the root node. If there is an error during parsing, the
+// NodeSet's Err field is set.
+func New(r io.Reader) NodeSet {
+	root, err := parse(r)
+	if err != nil {
+		return NodeSet{Err: err}
+	}
+	return NodeSet{
+		Data: func(yield func(*Node) bool) {
+			yield(root)
+		},
+	}
+}
+
+// parse decodes the XML token stream from r into a minimal Node tree.
+func parse(r io.Reader) (*Node, error) {
+	dec := xml.NewDecoder(r)
+	var root, cur *Node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &Node{Local: t.Name.Local, Space: t.Name.Space, Parent: cur}
+			for _, a := range t.Attr {
+				n.Attr = append(n.Attr, Attr{Name: a.Name.Local, Value: a.Value})
+			}
+			if cur != nil {
+				appendChild(cur, n)
+			} else if root == nil {
+				root = n
+			}
+			cur = n
+		case xml.CharData:
+			if cur != nil {
+				cur.text += string(t)
+			}
+		case xml.EndElement:
+			if cur != nil {
+				cur = cur.Parent
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("dql/xml: no root element")
+	}
+	return root, nil
+}
+
+func appendChild(parent, child *Node) {
+	if parent.FirstChild == nil {
+		parent.FirstChild = child
+		return
+	}
+	last := parent.FirstChild
+	for last.NextSibling != nil {
+		last = last.NextSibling
+	}
+	last.NextSibling = child
+}
+
+// Source creates a NodeSet from various types of sources:
+// - string: treated as an URL to read XML content from.
+// - []byte: treated as raw XML content.
+// - io.Reader: reads XML content from the reader.
+// - iter.Seq[*Node]: directly uses the provided sequence of nodes.
+// - NodeSet: returns the provided NodeSet as is.
+// If the source type is unsupported, it panics.
+func Source(r any) (ret NodeSet) {
+	switch v := r.(type) {
+	case string:
+		f, err := stream.Open(v)
+		if err != nil {
+			return NodeSet{Err: err}
+		}
+		defer f.Close()
+		return New(f)
+	case []byte:
+		return New(bytes.NewReader(v))
+	case io.Reader:
+		return New(v)
+	case iter.Seq[*Node]:
+		return NodeSet{Data: v}
+	case NodeSet:
+		return v
+	default:
+		panic("dql/xml.Source: unsupport source type")
+	}
+}
+
+// XGo_Enum returns an iterator over the nodes in the NodeSet.
+func (p NodeSet) XGo_Enum() iter.Seq[*Node] {
+	if p.Err != nil {
+		return nopIter[*Node]
+	}
+	return p.Data
+}
+
+// XGo_Namespace binds prefix to uri for this NodeSet, so that a subsequent
+// XGo_Node("prefix:local") matches elements whose namespace URI is uri.
+func (p NodeSet) XGo_Namespace(prefix, uri string) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	ns := make(map[string]string, len(p.ns)+1)
+	for k, v := range p.ns {
+		ns[k] = v
+	}
+	ns[prefix] = uri
+	return NodeSet{Data: p.Data, ns: ns}
+}
+
+// XGo_Node returns a NodeSet containing the child nodes with the specified
+// name. A name of the form "prefix:local" matches by the namespace URI bound
+// to prefix via XGo_Namespace; a bare name matches by local name only. It is
+// an error to use a prefix that hasn't been bound, rather than silently
+// falling back to matching the empty namespace (i.e. unqualified elements).
+func (p NodeSet) XGo_Node(name string) NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	prefix, local, qualified := strings.Cut(name, ":")
+	if !qualified {
+		local, prefix = prefix, ""
+	}
+	uri, bound := p.ns[prefix]
+	if qualified && !bound {
+		return NodeSet{Err: fmt.Errorf("dql/xml: unbound namespace prefix %q", prefix)}
+	}
+	return NodeSet{
+		ns: p.ns,
+		Data: func(yield func(*Node) bool) {
+			ok := true
+			p.Data(func(n *Node) bool {
+				n.ChildNodes()(func(c *Node) bool {
+					match := c.Local == local && (!qualified || c.Space == uri)
+					if match {
+						ok = yield(c)
+					}
+					return ok
+				})
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_Child returns a NodeSet containing all child nodes of the nodes in the NodeSet.
+func (p NodeSet) XGo_Child() NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	return NodeSet{
+		ns: p.ns,
+		Data: func(yield func(*Node) bool) {
+			ok := true
+			p.Data(func(n *Node) bool {
+				n.ChildNodes()(func(c *Node) bool {
+					ok = yield(c)
+					return ok
+				})
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_Any returns a NodeSet containing all descendant nodes of the nodes in
+// the NodeSet, including the nodes themselves.
+func (p NodeSet) XGo_Any() NodeSet {
+	if p.Err != nil {
+		return p
+	}
+	return NodeSet{
+		ns: p.ns,
+		Data: func(yield func(*Node) bool) {
+			ok := true
+			p.Data(func(n *Node) bool {
+				if ok = yield(n); ok {
+					n.Descendants()(func(c *Node) bool {
+						ok = yield(c)
+						return ok
+					})
+				}
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_Attr returns a ValueSet containing the values of the specified
+// attribute for each node in the NodeSet. If a node does not have the
+// specified attribute, the Value will contain ErrNotFound.
+func (p NodeSet) XGo_Attr(name string) util.ValueSet[string] {
+	if p.Err != nil {
+		return util.ValueSet[string]{Err: p.Err}
+	}
+	return util.ValueSet[string]{
+		Data: func(yield func(util.Value[string]) bool) {
+			ok := true
+			p.Data(func(n *Node) bool {
+				for _, a := range n.Attr {
+					if a.Name == name {
+						ok = yield(util.Value[string]{X_0: a.Value})
+						return ok
+					}
+				}
+				ok = yield(util.Value[string]{X_1: ErrNotFound})
+				return ok
+			})
+		},
+	}
+}
+
+// XGo_Text returns a ValueSet containing, for each node in the NodeSet, the
+// concatenated character data directly inside it; text inside its child
+// elements is not included.
+func (p NodeSet) XGo_Text() util.ValueSet[string] {
+	if p.Err != nil {
+		return util.ValueSet[string]{Err: p.Err}
+	}
+	return util.ValueSet[string]{
+		Data: func(yield func(util.Value[string]) bool) {
+			p.Data(func(n *Node) bool {
+				return yield(util.Value[string]{X_0: n.text})
+			})
+		},
+	}
+}
+
+// XGo_0 returns the first node in the NodeSet, or ErrNotFound if the set is empty.
+func (p NodeSet) XGo_0() (val *Node, err error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	err = ErrNotFound
+	p.Data(func(n *Node) bool {
+		val, err = n, nil
+		return false
+	})
+	return
+}
+
+// XGo_1 returns the first node in the NodeSet, or ErrNotFound if the set is empty.
+// If there is more than one node in the set, ErrMultiEntities is returned.
+func (p NodeSet) XGo_1() (val *Node, err error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	first := true
+	err = ErrNotFound
+	p.Data(func(n *Node) bool {
+		if first {
+			val, err = n, nil
+			first = false
+			return true
+		}
+		err = ErrMultiEntities
+		return false
+	})
+	return
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,88 @@
+package xml
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+const xmlTestDoc = `<root xmlns:a="urn:a">
+  <item id="1"><title>One</title></item>
+  <item id="2"><title>Two</title></item>
+  <a:item id="3"><title>Three</title></a:item>
+</root>`
+
+func TestNodeField(t *testing.T) {
+	doc := New(strings.NewReader(xmlTestDoc))
+	if doc.Err != nil {
+		t.Fatalf("New error: %v", doc.Err)
+	}
+
+	var ids []string
+	doc.XGo_Node("item").XGo_Enum()(func(n *Node) bool {
+		if v, ok := attrVal(n, "id"); ok {
+			ids = append(ids, v)
+		}
+		return true
+	})
+	sort.Strings(ids)
+	// A bare name matches by local name only, so a:item is included too.
+	if want := []string{"1", "2", "3"}; strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("XGo_Node(\"item\") ids = %v, want %v", ids, want)
+	}
+}
+
+func attrVal(n *Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestNodeQualifiedPrefix(t *testing.T) {
+	doc := New(strings.NewReader(xmlTestDoc)).XGo_Namespace("a", "urn:a")
+
+	got, err := doc.XGo_Node("a:item").XGo_0()
+	if err != nil {
+		t.Fatalf("XGo_Node(\"a:item\") error: %v", err)
+	}
+	if v, _ := attrVal(got, "id"); v != "3" {
+		t.Errorf("XGo_Node(\"a:item\") = id %q, want \"3\"", v)
+	}
+}
+
+func TestNodeUnboundPrefixIsAnError(t *testing.T) {
+	doc := New(strings.NewReader(xmlTestDoc))
+	ns := doc.XGo_Node("a:item")
+	if ns.Err == nil {
+		t.Fatal("expected an error for an unbound namespace prefix")
+	}
+}
+
+func TestAttrStopsAtFirstMismatch(t *testing.T) {
+	// Two "item" nodes, only the second of which has an "id" attribute - the
+	// first node's ErrNotFound yield must actually stop XGo_0 from picking up
+	// the second node's value.
+	const doc = `<root><item><title>no id here</title></item><item id="second"/></root>`
+	val, err := New(strings.NewReader(doc)).XGo_Node("item").XGo_Attr("id").XGo_0()
+	if err != ErrNotFound {
+		t.Fatalf("XGo_Attr(\"id\").XGo_0() = (%q, %v), want (\"\", ErrNotFound)", val, err)
+	}
+}
+
+func TestAttrNotFound(t *testing.T) {
+	doc := New(strings.NewReader(xmlTestDoc))
+	_, err := doc.XGo_Node("item").XGo_Attr("missing").XGo_0()
+	if err != ErrNotFound {
+		t.Errorf("XGo_Attr(\"missing\").XGo_0() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestInvalidXML(t *testing.T) {
+	doc := New(strings.NewReader("<root><unterminated></root>"))
+	if doc.Err == nil {
+		t.Fatal("expected an error for malformed XML")
+	}
+}